@@ -0,0 +1,305 @@
+package executor
+
+import (
+	"container/list"
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// LimitSample describes the outcome of a single Action executed under an
+// AdaptiveLimit executor.
+type LimitSample struct {
+	// Latency is how long the Action took to execute.
+	Latency time.Duration
+	// InFlight is the number of Actions executing when this Action started,
+	// including itself.
+	InFlight int
+	// Failed is true if the Action returned an error.
+	Failed bool
+}
+
+// A LimitAlgorithm computes the next concurrency limit of an AdaptiveLimit
+// executor from the current limit and the outcome of an Action. The result is
+// bounded by the executor's Min and Max. Update is never called concurrently,
+// so implementations may hold state without synchronization.
+type LimitAlgorithm interface {
+	Update(limit int, sample LimitSample) int
+}
+
+// AIMD creates an additive-increase/multiplicative-decrease LimitAlgorithm.
+// Each successful Action raises the limit by increase, provided the limit was
+// at least half utilized; each failed Action multiplies the limit by backoff,
+// which should be between zero and one.
+func AIMD(increase int, backoff float64) LimitAlgorithm {
+	return aimd{increase: increase, backoff: backoff}
+}
+
+type aimd struct {
+	increase int
+	backoff  float64
+}
+
+func (a aimd) Update(limit int, s LimitSample) int {
+	switch {
+	case s.Failed:
+		return int(float64(limit) * a.backoff)
+	case s.InFlight*2 >= limit:
+		return limit + a.increase
+	default:
+		return limit
+	}
+}
+
+// Gradient creates a LimitAlgorithm in the style of TCP Vegas, comparing the
+// latency of each Action against the lowest latency observed. While latencies
+// remain within tolerance times the lowest (eg, 2.0), the limit grows by its
+// square root, permitting a small queue; as latency rises beyond it, the limit
+// shrinks proportionally, to no less than half. Failed Actions halve the limit.
+func Gradient(tolerance float64) LimitAlgorithm {
+	return &gradient{tolerance: tolerance}
+}
+
+type gradient struct {
+	tolerance float64
+	min       time.Duration
+}
+
+func (g *gradient) Update(limit int, s LimitSample) int {
+	if s.Failed {
+		return limit / 2
+	}
+
+	if s.Latency <= 0 {
+		return limit
+	}
+
+	if g.min == 0 || s.Latency < g.min {
+		g.min = s.Latency
+	}
+
+	grad := math.Max(0.5, math.Min(1, g.tolerance*float64(g.min)/float64(s.Latency)))
+	next := float64(limit) * grad
+
+	// only probe for more capacity if the current limit is being utilized
+	if s.InFlight*2 >= limit {
+		next += math.Sqrt(float64(limit))
+	}
+
+	return int(next)
+}
+
+// AdaptiveConfig configures the behavior of the AdaptiveLimit executor.
+type AdaptiveConfig struct {
+	// Algorithm adjusts the limit after each Action. If nil, AIMD(1, 0.9) is
+	// used.
+	Algorithm LimitAlgorithm
+
+	// Initial is the starting concurrency limit. If zero, Min is used.
+	Initial int
+
+	// Min is the lowest the concurrency limit can fall. If zero, 1 is used.
+	Min int
+
+	// Max is the highest the concurrency limit can rise. If zero, the limit is
+	// unbounded.
+	Max int
+
+	// Stats, if provided, receives the current limit via the "<Name>.limit"
+	// counter. Each change is emitted as a delta, such that the sum of all
+	// emitted values is the current limit.
+	Stats StatSource
+
+	// Name distinguishes the limit's counter from those of other AdaptiveLimit
+	// executors sharing the same Stats. If empty, "adaptive" is used.
+	Name string
+}
+
+type adaptiveLimit struct {
+	ex  Interface
+	lim *adaptiveLimiter
+}
+
+// AdaptiveLimit decorates the passed in executor, limiting the number of
+// Actions executing concurrently. Unlike ControlFlow, the limit is not fixed:
+// it is adjusted by the configured LimitAlgorithm as Actions complete, based on
+// their latency and failures. Actions wait for the limit to permit them to
+// start, unless the ctx is cancelled.
+func AdaptiveLimit(e Interface, cfg AdaptiveConfig) Interface {
+	if cfg.Algorithm == nil {
+		cfg.Algorithm = AIMD(1, 0.9)
+	}
+
+	if cfg.Min <= 0 {
+		cfg.Min = 1
+	}
+
+	if cfg.Max <= 0 {
+		cfg.Max = math.MaxInt32
+	}
+
+	if cfg.Name == "" {
+		cfg.Name = "adaptive"
+	}
+
+	l := &adaptiveLimiter{
+		cfg:     cfg,
+		waiters: list.New(),
+	}
+
+	if cfg.Stats != nil {
+		l.counter = cfg.Stats.Counter(cfg.Name + ".limit")
+	}
+
+	l.set(cfg.Initial)
+
+	return &adaptiveLimit{ex: e, lim: l}
+}
+
+func (al *adaptiveLimit) Execute(ctx context.Context, actions ...Action) error {
+	wrapped := make([]Action, len(actions))
+
+	for i, a := range actions {
+		if na, ok := a.(NamedAction); ok {
+			wrapped[i] = namedLimitedAction{NamedAction: na, lim: al.lim}
+		} else {
+			wrapped[i] = limitedAction{Action: a, lim: al.lim}
+		}
+	}
+
+	return al.ex.Execute(ctx, wrapped...)
+}
+
+type namedLimitedAction struct {
+	NamedAction
+	lim *adaptiveLimiter
+}
+
+func (a namedLimitedAction) Execute(ctx context.Context) error {
+	return a.lim.execute(ctx, a.NamedAction)
+}
+
+func (a namedLimitedAction) Unwrap() Action { return a.NamedAction }
+
+type limitedAction struct {
+	Action
+	lim *adaptiveLimiter
+}
+
+func (a limitedAction) Execute(ctx context.Context) error {
+	return a.lim.execute(ctx, a.Action)
+}
+
+func (a limitedAction) Unwrap() Action { return a.Action }
+
+// adaptiveLimiter is a semaphore whose size is adjusted by a LimitAlgorithm.
+type adaptiveLimiter struct {
+	cfg     AdaptiveConfig
+	counter Counter
+
+	mtx      sync.Mutex
+	limit    int
+	inFlight int
+	waiters  *list.List // of chan struct{}, closed once the waiter may start
+}
+
+// execute runs a once permitted by the limit, updating the limit with its
+// outcome. Actions cancelled by the caller do not affect the limit.
+func (l *adaptiveLimiter) execute(ctx context.Context, a Action) error {
+	inFlight, err := l.acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = a.Execute(ctx)
+
+	l.release(ctx.Err() == nil, LimitSample{
+		Latency:  time.Since(start),
+		InFlight: inFlight,
+		Failed:   err != nil,
+	})
+
+	return err
+}
+
+// acquire waits for a slot under the limit, returning the number of Actions in
+// flight once acquired.
+func (l *adaptiveLimiter) acquire(ctx context.Context) (int, error) {
+	l.mtx.Lock()
+
+	if l.waiters.Len() == 0 && l.inFlight < l.limit {
+		l.inFlight++
+		n := l.inFlight
+		l.mtx.Unlock()
+		return n, nil
+	}
+
+	ready := make(chan struct{})
+	elem := l.waiters.PushBack(ready)
+	l.mtx.Unlock()
+
+	select {
+	case <-ready:
+		l.mtx.Lock()
+		defer l.mtx.Unlock()
+		return l.inFlight, nil
+	case <-ctx.Done():
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	select {
+	case <-ready:
+		// acquired after ctx was cancelled, so give the slot back
+		l.inFlight--
+		l.wake()
+	default:
+		l.waiters.Remove(elem)
+	}
+
+	return 0, ctx.Err()
+}
+
+// release frees a slot, updating the limit with sample if record is true.
+func (l *adaptiveLimiter) release(record bool, sample LimitSample) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	l.inFlight--
+	if record {
+		l.set(l.cfg.Algorithm.Update(l.limit, sample))
+	}
+	l.wake()
+}
+
+// set bounds and applies the new limit, emitting the change. The caller must
+// hold the lock.
+func (l *adaptiveLimiter) set(limit int) {
+	if limit < l.cfg.Min {
+		limit = l.cfg.Min
+	}
+
+	if limit > l.cfg.Max {
+		limit = l.cfg.Max
+	}
+
+	if delta := limit - l.limit; delta != 0 && l.counter != nil {
+		l.counter(delta)
+	}
+
+	l.limit = limit
+}
+
+// wake permits waiters to start while the limit allows. The caller must hold
+// the lock.
+func (l *adaptiveLimiter) wake() {
+	for l.inFlight < l.limit && l.waiters.Len() > 0 {
+		l.inFlight++
+		close(l.waiters.Remove(l.waiters.Front()).(chan struct{}))
+	}
+}
+
+var _ Interface = (*adaptiveLimit)(nil)
@@ -0,0 +1,122 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAIMD(t *testing.T) {
+	t.Parallel()
+
+	alg := AIMD(2, 0.5)
+
+	assert.Equal(t, 12, alg.Update(10, LimitSample{InFlight: 5}))
+	assert.Equal(t, 10, alg.Update(10, LimitSample{InFlight: 4}))
+	assert.Equal(t, 5, alg.Update(10, LimitSample{InFlight: 10, Failed: true}))
+}
+
+func TestGradient(t *testing.T) {
+	t.Parallel()
+
+	alg := Gradient(1)
+
+	// the first sample sets the baseline latency
+	assert.Equal(t, 20, alg.Update(16, LimitSample{Latency: time.Millisecond, InFlight: 16}))
+	assert.Equal(t, 16, alg.Update(16, LimitSample{Latency: time.Millisecond, InFlight: 1}))
+	assert.Equal(t, 12, alg.Update(16, LimitSample{Latency: 4 * time.Millisecond, InFlight: 16}))
+	assert.Equal(t, 8, alg.Update(16, LimitSample{Latency: 2 * time.Millisecond, InFlight: 1}))
+	assert.Equal(t, 8, alg.Update(16, LimitSample{Failed: true}))
+}
+
+func TestAdaptiveLimit(t *testing.T) {
+	t.Parallel()
+
+	t.Run("bounds concurrency", func(t *testing.T) {
+		t.Parallel()
+
+		ex := AdaptiveLimit(Parallel{}, AdaptiveConfig{Min: 2, Max: 2})
+
+		var cur, peak int64
+		act := ActionFunc(func(context.Context) error {
+			n := atomic.AddInt64(&cur, 1)
+			for {
+				p := atomic.LoadInt64(&peak)
+				if n <= p || atomic.CompareAndSwapInt64(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt64(&cur, -1)
+			return nil
+		})
+
+		assert.NoError(t, ex.Execute(context.Background(), act, act, act, act, act, act))
+		assert.Equal(t, int64(2), atomic.LoadInt64(&peak))
+	})
+
+	t.Run("adjusts limit", func(t *testing.T) {
+		t.Parallel()
+
+		ss := new(fakeStatSource)
+		ex := AdaptiveLimit(Sequential{FailOpen: true}, AdaptiveConfig{
+			Algorithm: AIMD(1, 0.5),
+			Initial:   4,
+			Max:       5,
+			Stats:     ss,
+		})
+		ss.testCounter(t, "adaptive.limit", 4)
+
+		noop := ActionFunc(func(context.Context) error { return nil })
+		fail := ActionFunc(func(context.Context) error { return errors.New("fail") })
+
+		// sequential actions only utilize a limit of two or less
+		assert.NoError(t, ex.Execute(context.Background(), noop))
+		ss.testCounter(t, "adaptive.limit", 4)
+
+		assert.Error(t, ex.Execute(context.Background(), fail, fail, fail))
+		ss.testCounter(t, "adaptive.limit", 1)
+
+		assert.NoError(t, ex.Execute(context.Background(), noop, noop, noop, noop, noop))
+		ss.testCounter(t, "adaptive.limit", 3)
+	})
+
+	t.Run("named", func(t *testing.T) {
+		t.Parallel()
+
+		ss := new(fakeStatSource)
+		AdaptiveLimit(Sequential{}, AdaptiveConfig{Initial: 4, Stats: ss, Name: "payments"})
+		AdaptiveLimit(Sequential{}, AdaptiveConfig{Initial: 2, Stats: ss, Name: "search"})
+
+		ss.testCounter(t, "payments.limit", 4)
+		ss.testCounter(t, "search.limit", 2)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		ex := AdaptiveLimit(Parallel{}, AdaptiveConfig{Max: 1})
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		go ex.Execute(context.Background(), ActionFunc(func(context.Context) error {
+			close(started)
+			<-release
+			return nil
+		}))
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		err := ex.Execute(ctx, ActionFunc(func(context.Context) error { return nil }))
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+		close(release)
+		assert.NoError(t, ex.Execute(context.Background(), ActionFunc(func(context.Context) error { return nil })))
+	})
+}
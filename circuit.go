@@ -0,0 +1,337 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrCircuitOpen is returned for Actions rejected by an open circuit breaker.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState describes the state of a circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed permits all Actions, tracking their failures.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects all Actions until the cool-down elapses.
+	CircuitOpen
+	// CircuitHalfOpen permits a limited number of trial Actions to determine
+	// whether the breaker should close again.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig configures the behavior of the CircuitBreaker executor.
+type BreakerConfig struct {
+	// FailureRatio is the ratio of failed to total Actions that trips a closed
+	// breaker. If zero, 0.5 is used.
+	FailureRatio float64
+
+	// MinRequests is the number of Actions that must complete within the
+	// Window before the breaker can trip. If zero, 1 is used.
+	MinRequests int
+
+	// Window is the interval after which the failure counts of a closed
+	// breaker are reset. If zero, the counts are never reset.
+	Window time.Duration
+
+	// Cooldown is how long a breaker stays open before permitting trial
+	// Actions.
+	Cooldown time.Duration
+
+	// HalfOpenRequests is the number of trial Actions permitted while
+	// half-open. If all succeed, the breaker closes; any failure reopens it.
+	// If zero, 1 is used.
+	HalfOpenRequests int
+
+	// Stats, if provided, receives a counter for each state change, named
+	// after the Type and the new state (eg, "foo.circuit.open").
+	Stats StatSource
+
+	// OnStateChange, if provided, is called whenever the breaker for a Type
+	// changes state.
+	OnStateChange func(typ string, from, to CircuitState)
+}
+
+type circuitBreaker struct {
+	ex  Interface
+	cfg BreakerConfig
+
+	mtx      sync.Mutex
+	breakers map[string]*breaker
+}
+
+// CircuitBreaker decorates the passed in executor, tracking the failure rate
+// of NamedActions per Type. Once the breaker for a Type opens, Actions of that
+// Type are rejected with ErrCircuitOpen without reaching the decorated
+// executor. Actions that are not NamedActions are always permitted.
+func CircuitBreaker(e Interface, cfg BreakerConfig) Interface {
+	if cfg.FailureRatio == 0 {
+		cfg.FailureRatio = 0.5
+	}
+
+	if cfg.MinRequests == 0 {
+		cfg.MinRequests = 1
+	}
+
+	if cfg.HalfOpenRequests == 0 {
+		cfg.HalfOpenRequests = 1
+	}
+
+	return &circuitBreaker{
+		ex:       e,
+		cfg:      cfg,
+		breakers: make(map[string]*breaker),
+	}
+}
+
+func (cb *circuitBreaker) Execute(ctx context.Context, actions ...Action) error {
+	allowed := make([]Action, 0, len(actions))
+	idx := make([]int, 0, len(actions))
+	var tracked []*breakerAction
+	var rejected MultiError
+
+	for i, a := range actions {
+		na, ok := a.(NamedAction)
+		if !ok {
+			allowed = append(allowed, a)
+			idx = append(idx, i)
+			continue
+		}
+
+		b := cb.get(na.Type())
+		gen, ok := b.allow(time.Now())
+		if !ok {
+			rejected = append(rejected, newActionError(i, a, ErrCircuitOpen))
+			continue
+		}
+
+		ba := &breakerAction{NamedAction: na, b: b, gen: gen}
+		tracked = append(tracked, ba)
+		allowed = append(allowed, ba)
+		idx = append(idx, i)
+	}
+
+	var err error
+	if len(allowed) > 0 {
+		err = remapErrors(cb.ex.Execute(ctx, allowed...), idx)
+	}
+
+	// Actions that never ran must not hold onto a half-open trial. The wrapped
+	// executor may return before running an Action that later starts anyway,
+	// so whichever comes first claims the Action's outcome.
+	for _, ba := range tracked {
+		if ba.claim() {
+			ba.b.record(time.Now(), ba.gen, outcomeIgnored)
+		}
+	}
+
+	return mergeErrors(err, rejected)
+}
+
+func (cb *circuitBreaker) get(typ string) *breaker {
+	cb.mtx.Lock()
+	defer cb.mtx.Unlock()
+
+	b, ok := cb.breakers[typ]
+	if !ok {
+		b = &breaker{typ: typ, cfg: &cb.cfg}
+		if cb.cfg.Stats != nil {
+			b.counters = map[CircuitState]Counter{
+				CircuitClosed:   cb.cfg.Stats.Counter(typ + ".circuit." + CircuitClosed.String()),
+				CircuitOpen:     cb.cfg.Stats.Counter(typ + ".circuit." + CircuitOpen.String()),
+				CircuitHalfOpen: cb.cfg.Stats.Counter(typ + ".circuit." + CircuitHalfOpen.String()),
+			}
+		}
+		cb.breakers[typ] = b
+	}
+
+	return b
+}
+
+type breakerAction struct {
+	NamedAction
+	b       *breaker
+	gen     uint64 // the breaker's generation when the Action was permitted
+	claimed uint32 // set once the Action's outcome is to be recorded
+}
+
+// claim reports whether the caller is the first to claim the Action's outcome,
+// which must then be recorded exactly once.
+func (a *breakerAction) claim() bool {
+	return atomic.CompareAndSwapUint32(&a.claimed, 0, 1)
+}
+
+func (a *breakerAction) Execute(ctx context.Context) error {
+	if !a.claim() {
+		// already recorded as ignored
+		return a.NamedAction.Execute(ctx)
+	}
+
+	err := a.NamedAction.Execute(ctx)
+
+	switch {
+	case err == nil:
+		a.b.record(time.Now(), a.gen, outcomeSuccess)
+	case ctx.Err() != nil: // the caller gave up, not the Action's fault
+		a.b.record(time.Now(), a.gen, outcomeIgnored)
+	default:
+		a.b.record(time.Now(), a.gen, outcomeFailure)
+	}
+
+	return err
+}
+
+func (a *breakerAction) Unwrap() Action { return a.NamedAction }
+
+type outcome int
+
+const (
+	outcomeSuccess outcome = iota
+	outcomeFailure
+	outcomeIgnored
+)
+
+// breaker tracks the state for a single Type.
+type breaker struct {
+	typ      string
+	cfg      *BreakerConfig
+	counters map[CircuitState]Counter
+
+	mtx        sync.Mutex
+	state      CircuitState
+	generation uint64    // incremented on each change of state
+	since      time.Time // start of the current window or state
+	requests   int
+	failures   int
+	trials     int
+	successes  int
+}
+
+// allow reports whether an Action may proceed, consuming a trial if the
+// breaker is half-open. The returned generation must be passed to record with
+// the Action's outcome.
+func (b *breaker) allow(now time.Time) (uint64, bool) {
+	b.mtx.Lock()
+	from := b.state
+
+	if b.state == CircuitOpen && now.Sub(b.since) >= b.cfg.Cooldown {
+		b.reset(CircuitHalfOpen, now)
+	}
+
+	var ok bool
+	switch b.state {
+	case CircuitClosed:
+		if b.cfg.Window > 0 && now.Sub(b.since) >= b.cfg.Window {
+			b.reset(CircuitClosed, now)
+		}
+		ok = true
+	case CircuitHalfOpen:
+		if b.trials < b.cfg.HalfOpenRequests {
+			b.trials++
+			ok = true
+		}
+	}
+
+	gen, to := b.generation, b.state
+	b.mtx.Unlock()
+
+	b.notify(from, to)
+	return gen, ok
+}
+
+// record updates the breaker with the outcome of an Action permitted in the
+// given generation. Outcomes of Actions permitted before the breaker last
+// changed state are dropped, as they neither hold a trial nor describe the
+// current state.
+func (b *breaker) record(now time.Time, gen uint64, o outcome) {
+	b.mtx.Lock()
+	from := b.state
+
+	if gen != b.generation {
+		b.mtx.Unlock()
+		return
+	}
+
+	switch b.state {
+	case CircuitClosed:
+		if o == outcomeIgnored {
+			break
+		}
+
+		b.requests++
+		if o == outcomeFailure {
+			b.failures++
+		}
+
+		if b.requests >= b.cfg.MinRequests &&
+			float64(b.failures)/float64(b.requests) >= b.cfg.FailureRatio {
+			b.reset(CircuitOpen, now)
+		}
+	case CircuitHalfOpen:
+		switch o {
+		case outcomeIgnored:
+			if b.trials > 0 {
+				b.trials--
+			}
+		case outcomeFailure:
+			b.reset(CircuitOpen, now)
+		case outcomeSuccess:
+			b.successes++
+			if b.successes >= b.cfg.HalfOpenRequests {
+				b.reset(CircuitClosed, now)
+			}
+		}
+	}
+
+	to := b.state
+	b.mtx.Unlock()
+
+	b.notify(from, to)
+}
+
+// reset transitions the breaker to state, clearing all counts. The caller
+// must hold the lock.
+func (b *breaker) reset(state CircuitState, now time.Time) {
+	if state != b.state {
+		b.generation++
+	}
+
+	b.state = state
+	b.since = now
+	b.requests, b.failures = 0, 0
+	b.trials, b.successes = 0, 0
+}
+
+// notify reports a state change, if any, to the configured stats and hook.
+func (b *breaker) notify(from, to CircuitState) {
+	if from == to {
+		return
+	}
+
+	if b.counters != nil {
+		b.counters[to](1)
+	}
+
+	if b.cfg.OnStateChange != nil {
+		b.cfg.OnStateChange(b.typ, from, to)
+	}
+}
+
+var _ Interface = (*circuitBreaker)(nil)
@@ -0,0 +1,206 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	expected := errors.New("some error")
+	fail := func(context.Context) error { return expected }
+	noop := func(context.Context) error { return nil }
+
+	t.Run("trips and recovers", func(t *testing.T) {
+		t.Parallel()
+
+		var mtx sync.Mutex
+		var changes []CircuitState
+
+		ss := new(fakeStatSource)
+		ex := CircuitBreaker(Sequential{}, BreakerConfig{
+			MinRequests: 2,
+			Cooldown:    10 * time.Millisecond,
+			Stats:       ss,
+			OnStateChange: func(typ string, from, to CircuitState) {
+				assert.Equal(t, "foo", typ)
+				mtx.Lock()
+				changes = append(changes, to)
+				mtx.Unlock()
+			},
+		})
+
+		var ct uint32
+		counted := func(fn ActionFunc) ActionFunc {
+			return func(ctx context.Context) error {
+				atomic.AddUint32(&ct, 1)
+				return fn(ctx)
+			}
+		}
+
+		ctx := context.Background()
+
+		assert.Error(t, ex.Execute(ctx, Named("foo", "1", counted(fail))))
+		assert.Error(t, ex.Execute(ctx, Named("foo", "2", counted(fail))))
+
+		err := ex.Execute(ctx, Named("foo", "3", counted(noop)))
+		assert.True(t, errors.Is(err, ErrCircuitOpen))
+		assert.Equal(t, uint32(2), ct, "rejected actions should not execute")
+
+		assert.NoError(t, ex.Execute(ctx, Named("bar", "1", noop)),
+			"other types should not be affected")
+
+		time.Sleep(20 * time.Millisecond)
+
+		assert.NoError(t, ex.Execute(ctx, Named("foo", "4", counted(noop))))
+		assert.Equal(t, uint32(3), ct)
+
+		assert.Equal(t, []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitClosed}, changes)
+		ss.testCounter(t, "foo.circuit.open", 1)
+		ss.testCounter(t, "foo.circuit.half_open", 1)
+		ss.testCounter(t, "foo.circuit.closed", 1)
+	})
+
+	t.Run("half-open failure", func(t *testing.T) {
+		t.Parallel()
+
+		ex := CircuitBreaker(Sequential{}, BreakerConfig{Cooldown: 10 * time.Millisecond})
+		ctx := context.Background()
+
+		assert.Error(t, ex.Execute(ctx, Named("foo", "1", fail)))
+		time.Sleep(20 * time.Millisecond)
+
+		err := ex.Execute(ctx, Named("foo", "2", fail), Named("foo", "3", noop))
+		assert.True(t, errors.Is(err, expected))
+
+		err = ex.Execute(ctx, Named("foo", "4", noop))
+		assert.True(t, errors.Is(err, ErrCircuitOpen))
+	})
+
+	t.Run("detached execution", func(t *testing.T) {
+		t.Parallel()
+
+		// the wrapped executor returns before running the actions when detached,
+		// like a Debouncer whose caller gave up.
+		var detach bool
+		ran := make(chan struct{})
+		ex := executorFunc(func(ctx context.Context, actions ...Action) error {
+			if !detach {
+				return Sequential{}.Execute(ctx, actions...)
+			}
+
+			go func() {
+				defer close(ran)
+				time.Sleep(5 * time.Millisecond)
+				Sequential{}.Execute(context.Background(), actions...)
+			}()
+			return nil
+		})
+
+		var mtx sync.Mutex
+		var changes []CircuitState
+
+		cb := CircuitBreaker(ex, BreakerConfig{
+			Cooldown: 10 * time.Millisecond,
+			OnStateChange: func(typ string, from, to CircuitState) {
+				mtx.Lock()
+				changes = append(changes, to)
+				mtx.Unlock()
+			},
+		})
+		ctx := context.Background()
+
+		assert.Error(t, cb.Execute(ctx, Named("foo", "1", fail)))
+		time.Sleep(20 * time.Millisecond)
+
+		// the trial is released when the call returns, so its late success is not
+		// recorded a second time
+		detach = true
+		assert.NoError(t, cb.Execute(ctx, Named("foo", "2", noop)))
+		<-ran
+
+		mtx.Lock()
+		assert.Equal(t, []CircuitState{CircuitOpen, CircuitHalfOpen}, changes)
+		mtx.Unlock()
+
+		detach = false
+		assert.NoError(t, cb.Execute(ctx, Named("foo", "3", noop)))
+	})
+
+	t.Run("stale outcome", func(t *testing.T) {
+		t.Parallel()
+
+		ex := CircuitBreaker(Sequential{}, BreakerConfig{Cooldown: 10 * time.Millisecond})
+		ctx := context.Background()
+
+		// block starts an Action that runs until the returned func is called
+		block := func(id string) func() error {
+			started := make(chan struct{})
+			release := make(chan struct{})
+			res := make(chan error, 1)
+
+			go func() {
+				res <- ex.Execute(ctx, Named("foo", id, func(context.Context) error {
+					close(started)
+					<-release
+					return nil
+				}))
+			}()
+
+			<-started
+			return func() error {
+				close(release)
+				return <-res
+			}
+		}
+
+		// admitted while closed, completing once the breaker is half-open
+		stale := block("1")
+
+		assert.Error(t, ex.Execute(ctx, Named("foo", "2", fail)))
+		time.Sleep(20 * time.Millisecond)
+
+		trial := block("3")
+		assert.NoError(t, stale())
+
+		err := ex.Execute(ctx, Named("foo", "4", noop))
+		assert.True(t, errors.Is(err, ErrCircuitOpen),
+			"a stale success should not close the breaker")
+
+		assert.NoError(t, trial())
+		assert.NoError(t, ex.Execute(ctx, Named("foo", "5", noop)))
+	})
+
+	t.Run("rejections merge with fail open", func(t *testing.T) {
+		t.Parallel()
+
+		ex := CircuitBreaker(Parallel{FailOpen: true}, BreakerConfig{Cooldown: time.Hour})
+		ctx := context.Background()
+
+		assert.Error(t, ex.Execute(ctx, Named("foo", "1", fail)))
+
+		err := ex.Execute(ctx,
+			ActionFunc(fail),
+			Named("foo", "2", noop),
+			Named("bar", "3", fail),
+			Named("foo", "4", noop))
+
+		var me MultiError
+		if assert.True(t, errors.As(err, &me)) && assert.Len(t, me, 4) {
+			for i, ae := range me {
+				assert.Equal(t, i, ae.Index)
+			}
+			assert.Equal(t, ErrCircuitOpen, me[1].Err)
+			assert.Equal(t, expected, me[2].Err)
+			assert.Equal(t, "bar", me[2].Type)
+			assert.Equal(t, ErrCircuitOpen, me[3].Err)
+		}
+	})
+}
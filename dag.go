@@ -0,0 +1,293 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// ErrSkipped is returned for Actions that were not executed because one of
+	// their dependencies failed.
+	ErrSkipped = errors.New("action skipped")
+
+	// ErrDuplicateID is returned by DAG if multiple Actions share an ID.
+	ErrDuplicateID = errors.New("duplicate action ID")
+
+	// ErrMissingDependency is returned by DAG if an Action depends on an ID
+	// not present in the Actions passed to Execute.
+	ErrMissingDependency = errors.New("missing dependency")
+
+	// ErrDependencyCycle is returned by DAG if the dependencies between Actions
+	// form a cycle.
+	ErrDependencyCycle = errors.New("dependency cycle")
+)
+
+// A DependentAction describes a NamedAction that may only be executed after
+// the Actions with the IDs it depends on have succeeded.
+type DependentAction interface {
+	NamedAction
+
+	// DependsOn returns the IDs of the Actions that must succeed before this
+	// Action is executed.
+	DependsOn() []string
+}
+
+type dependentAction struct {
+	NamedAction
+	deps []string
+}
+
+func (a dependentAction) DependsOn() []string { return a.deps }
+func (a dependentAction) Unwrap() Action      { return a.NamedAction }
+
+// DependsOn creates a DependentAction, requiring the Actions with the
+// specified IDs to succeed before a is executed.
+func DependsOn(a NamedAction, ids ...string) DependentAction {
+	return dependentAction{
+		NamedAction: a,
+		deps:        ids,
+	}
+}
+
+type dag struct {
+	ex Interface
+}
+
+// DAG creates an executor for Actions with dependencies on one another,
+// described by DependentAction. Each Action is passed to the wrapped executor
+// as soon as all of its dependencies have succeeded; Actions downstream of a
+// failure are skipped with ErrSkipped. Before anything is executed, the
+// dependencies are checked for duplicate or missing IDs and cycles.
+//
+// Independent Actions may be executed via concurrent calls to the wrapped
+// executor, so its Execute method must be safe for concurrent use. All failed
+// and skipped Actions are returned as a MultiError.
+func DAG(e Interface) Interface {
+	return dag{ex: e}
+}
+
+func (d dag) Execute(ctx context.Context, actions ...Action) error {
+	g, err := newDagGraph(actions)
+	if err != nil {
+		return err
+	}
+
+	s := &dagSchedule{
+		ex:        d.ex,
+		ctx:       ctx,
+		actions:   actions,
+		graph:     g,
+		remaining: append([]int(nil), g.indegree...),
+		done:      make([]bool, len(actions)),
+		errs:      make([]error, len(actions)),
+	}
+
+	var roots []int
+	for i, deg := range g.indegree {
+		if deg == 0 {
+			roots = append(roots, i)
+		}
+	}
+
+	s.submit(roots)
+	s.wg.Wait()
+
+	return collectErrors(actions, s.errs)
+}
+
+// dagGraph describes the dependencies between a batch of Actions.
+type dagGraph struct {
+	// indegree is the number of dependencies of each Action.
+	indegree []int
+	// dependents lists the Actions that depend on each Action.
+	dependents [][]int
+}
+
+// newDagGraph resolves the dependencies of the actions, returning an error if
+// any are missing or if they form a cycle.
+func newDagGraph(actions []Action) (*dagGraph, error) {
+	ids := make(map[string]int, len(actions))
+	for i, a := range actions {
+		na, ok := a.(NamedAction)
+		if !ok {
+			continue
+		}
+
+		if _, dupe := ids[na.ID()]; dupe {
+			return nil, fmt.Errorf("%w: %q", ErrDuplicateID, na.ID())
+		}
+		ids[na.ID()] = i
+	}
+
+	g := &dagGraph{
+		indegree:   make([]int, len(actions)),
+		dependents: make([][]int, len(actions)),
+	}
+
+	for i, a := range actions {
+		da, ok := asAction[DependentAction](a)
+		if !ok {
+			continue
+		}
+
+		for _, id := range da.DependsOn() {
+			dep, ok := ids[id]
+			if !ok {
+				return nil, fmt.Errorf("%w: %q depends on %q", ErrMissingDependency, da.ID(), id)
+			}
+
+			g.indegree[i]++
+			g.dependents[dep] = append(g.dependents[dep], i)
+		}
+	}
+
+	// Kahn's algorithm: if not every action can be visited in topological
+	// order, the remainder are part of (or downstream of) a cycle.
+	indegree := append([]int(nil), g.indegree...)
+	queue := make([]int, 0, len(actions))
+	for i, deg := range indegree {
+		if deg == 0 {
+			queue = append(queue, i)
+		}
+	}
+
+	for n := 0; n < len(queue); n++ {
+		for _, dep := range g.dependents[queue[n]] {
+			if indegree[dep]--; indegree[dep] == 0 {
+				queue = append(queue, dep)
+			}
+		}
+	}
+
+	if len(queue) < len(actions) {
+		for i, deg := range indegree {
+			if deg > 0 {
+				return nil, fmt.Errorf("%w: involving %q", ErrDependencyCycle, actions[i].(NamedAction).ID())
+			}
+		}
+	}
+
+	return g, nil
+}
+
+// dagSchedule tracks the progress of a single call to DAG's Execute.
+type dagSchedule struct {
+	ex      Interface
+	ctx     context.Context
+	actions []Action
+	graph   *dagGraph
+	wg      sync.WaitGroup
+
+	mtx       sync.Mutex
+	remaining []int
+	done      []bool
+	errs      []error
+}
+
+// submit executes the Actions at the given indices on the wrapped executor in
+// a new goroutine. Actions that were never executed by the wrapped executor
+// are failed with its error, or skipped if it failed due to another Action.
+func (s *dagSchedule) submit(batch []int) {
+	if len(batch) == 0 {
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		wrapped := make([]Action, len(batch))
+		for k, i := range batch {
+			if na, ok := s.actions[i].(NamedAction); ok {
+				wrapped[k] = namedDagAction{NamedAction: na, s: s, idx: i}
+			} else {
+				wrapped[k] = dagAction{Action: s.actions[i], s: s, idx: i}
+			}
+		}
+
+		err := s.ex.Execute(s.ctx, wrapped...)
+
+		var ae *ActionError
+		if err == nil || errors.As(err, &ae) {
+			err = ErrSkipped
+		}
+
+		for _, i := range batch {
+			s.complete(i, err)
+		}
+	}()
+}
+
+// complete records the result of the Action at index i, submitting any
+// dependents that are now ready, or skipping them if err is not nil. If the
+// Action was already completed, this is a no-op.
+func (s *dagSchedule) complete(i int, err error) {
+	s.mtx.Lock()
+
+	if s.done[i] {
+		s.mtx.Unlock()
+		return
+	}
+
+	s.done[i] = true
+	s.errs[i] = err
+
+	var ready []int
+	if err != nil {
+		s.skip(i)
+	} else {
+		for _, dep := range s.graph.dependents[i] {
+			if s.remaining[dep]--; s.remaining[dep] == 0 && !s.done[dep] {
+				ready = append(ready, dep)
+			}
+		}
+	}
+
+	s.mtx.Unlock()
+
+	s.submit(ready)
+}
+
+// skip fails all Actions downstream of the Action at index i. The caller must
+// hold the lock.
+func (s *dagSchedule) skip(i int) {
+	for _, dep := range s.graph.dependents[i] {
+		if !s.done[dep] {
+			s.done[dep] = true
+			s.errs[dep] = ErrSkipped
+			s.skip(dep)
+		}
+	}
+}
+
+type namedDagAction struct {
+	NamedAction
+	s   *dagSchedule
+	idx int
+}
+
+func (a namedDagAction) Execute(ctx context.Context) error {
+	err := a.NamedAction.Execute(ctx)
+	a.s.complete(a.idx, err)
+	return err
+}
+
+func (a namedDagAction) Unwrap() Action { return a.NamedAction }
+
+type dagAction struct {
+	Action
+	s   *dagSchedule
+	idx int
+}
+
+func (a dagAction) Execute(ctx context.Context) error {
+	err := a.Action.Execute(ctx)
+	a.s.complete(a.idx, err)
+	return err
+}
+
+func (a dagAction) Unwrap() Action { return a.Action }
+
+var _ Interface = dag{}
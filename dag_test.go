@@ -0,0 +1,156 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDAG(t *testing.T) {
+	t.Parallel()
+
+	type recorder struct {
+		mtx   sync.Mutex
+		order []string
+	}
+
+	record := func(r *recorder, id string, err error) NamedAction {
+		return Named("step", id, func(context.Context) error {
+			r.mtx.Lock()
+			r.order = append(r.order, id)
+			r.mtx.Unlock()
+			return err
+		})
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ex, done := Pool(1)
+		defer done()
+
+		r := new(recorder)
+		err := DAG(ex).Execute(context.Background(),
+			DependsOn(record(r, "d", nil), "b", "c"),
+			DependsOn(record(r, "b", nil), "a"),
+			record(r, "a", nil),
+			DependsOn(record(r, "c", nil), "a"))
+
+		assert.NoError(t, err)
+		if assert.Len(t, r.order, 4) {
+			assert.Equal(t, "a", r.order[0])
+			assert.Equal(t, "d", r.order[3])
+		}
+	})
+
+	t.Run("as soon as ready", func(t *testing.T) {
+		t.Parallel()
+
+		unblock := make(chan struct{})
+
+		err := DAG(Parallel{}).Execute(context.Background(),
+			Named("step", "slow", func(context.Context) error {
+				<-unblock
+				return nil
+			}),
+			Named("step", "fast", func(context.Context) error { return nil }),
+			DependsOn(Named("step", "after fast", func(context.Context) error {
+				close(unblock)
+				return nil
+			}), "fast"))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("skips downstream", func(t *testing.T) {
+		t.Parallel()
+
+		expected := errors.New("some error")
+
+		r := new(recorder)
+		err := DAG(Parallel{}).Execute(context.Background(),
+			record(r, "a", expected),
+			DependsOn(record(r, "b", nil), "a"),
+			DependsOn(record(r, "c", nil), "b"),
+			record(r, "d", nil),
+			DependsOn(record(r, "e", nil), "d"))
+
+		var me MultiError
+		if assert.True(t, errors.As(err, &me)) && assert.Len(t, me, 3) {
+			assert.Equal(t, expected, me[0].Err)
+			assert.Equal(t, "b", me[1].ID)
+			assert.Equal(t, ErrSkipped, me[1].Err)
+			assert.Equal(t, "c", me[2].ID)
+			assert.Equal(t, ErrSkipped, me[2].Err)
+		}
+
+		assert.ElementsMatch(t, []string{"a", "d", "e"}, r.order)
+	})
+
+	t.Run("wrapped", func(t *testing.T) {
+		t.Parallel()
+
+		r := new(recorder)
+		slow := Named("step", "a", func(ctx context.Context) error {
+			time.Sleep(5 * time.Millisecond)
+			return record(r, "a", nil).Execute(ctx)
+		})
+
+		err := Metrics(DAG(Parallel{}), stubSource{}).Execute(context.Background(),
+			DependsOn(record(r, "b", nil), "a"),
+			slow)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, r.order)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		r := new(recorder)
+		err := DAG(Sequential{}).Execute(ctx,
+			Named("step", "a", func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}),
+			DependsOn(record(r, "b", nil), "a"))
+
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.True(t, errors.Is(err, ErrSkipped))
+		assert.Empty(t, r.order)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		r := new(recorder)
+
+		tests := []struct {
+			expected error
+			actions  []Action
+		}{
+			{ErrDuplicateID, []Action{record(r, "a", nil), record(r, "a", nil)}},
+			{ErrMissingDependency, []Action{DependsOn(record(r, "a", nil), "b")}},
+			{ErrDependencyCycle, []Action{
+				record(r, "a", nil),
+				DependsOn(record(r, "b", nil), "a", "d"),
+				DependsOn(record(r, "c", nil), "b"),
+				DependsOn(record(r, "d", nil), "c"),
+			}},
+		}
+
+		for _, test := range tests {
+			err := DAG(Parallel{}).Execute(context.Background(), test.actions...)
+			assert.True(t, errors.Is(err, test.expected), "expected %v, got %v", test.expected, err)
+		}
+
+		assert.Empty(t, r.order)
+	})
+}
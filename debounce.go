@@ -2,54 +2,376 @@ package executor
 
 import (
 	"context"
+	"strconv"
+	"sync"
+	"time"
 
 	"golang.org/x/sync/singleflight"
 )
 
-// Debounce wraps an Executor Interface, preventing duplicate NamedActions
-// from running concurrently, even from concurrent calls to Execute.
-func Debounce(e Interface) Interface {
-	return debouncer{
-		ex: e,
-		sf: new(singleflight.Group),
+// A DebounceOption configures the behavior of a Debouncer.
+type DebounceOption func(*Debouncer)
+
+// DebounceTTL causes the Debouncer to memoize the success of an Action for
+// ttl, during which its duplicates succeed without being executed.
+func DebounceTTL(ttl time.Duration) DebounceOption {
+	return func(d *Debouncer) { d.ttl = ttl }
+}
+
+// DebounceTypeTTL overrides the DebounceTTL for NamedActions of the given
+// Type. A zero ttl disables memoization for the Type.
+func DebounceTypeTTL(typ string, ttl time.Duration) DebounceOption {
+	return func(d *Debouncer) { d.typeTTLs[typ] = ttl }
+}
+
+// DebounceErrorTTL causes the Debouncer to memoize the failure of an Action
+// for ttl, during which its duplicates return the same error without being
+// executed. This is typically shorter than the TTL of successes. Failures due
+// to the cancellation of all callers are never memoized.
+func DebounceErrorTTL(ttl time.Duration) DebounceOption {
+	return func(d *Debouncer) { d.errorTTL = ttl }
+}
+
+// DebounceKey causes the Debouncer to identify duplicate Actions by the key
+// returned by fn, in place of KeyByTypeAndID. Actions without a key are never
+// debounced.
+func DebounceKey(fn KeyFunc) DebounceOption {
+	return func(d *Debouncer) { d.key = fn }
+}
+
+// A KeyedAction describes an Action with an explicit key identifying its
+// duplicates, permitting Actions other than NamedActions to be debounced.
+type KeyedAction interface {
+	Action
+
+	// Key returns the key shared by duplicates of this Action.
+	Key() string
+}
+
+type keyedAction struct {
+	Action
+	key string
+}
+
+func (a keyedAction) Key() string    { return a.key }
+func (a keyedAction) Unwrap() Action { return a.Action }
+
+type namedKeyedAction struct {
+	NamedAction
+	key string
+}
+
+func (a namedKeyedAction) Key() string    { return a.key }
+func (a namedKeyedAction) Unwrap() Action { return a.NamedAction }
+
+// Keyed creates a KeyedAction with the specified key. If a is a NamedAction,
+// so is the returned KeyedAction.
+func Keyed(a Action, key string) KeyedAction {
+	if na, ok := a.(NamedAction); ok {
+		return namedKeyedAction{NamedAction: na, key: key}
+	}
+	return keyedAction{Action: a, key: key}
+}
+
+// KeyByTypeAndID is the default KeyFunc of a Debouncer. KeyedActions are keyed
+// by their Key, and other NamedActions by their Type and ID. The keys are
+// encoded such that they never collide between different Types and IDs, or
+// between KeyedActions and NamedActions. Other Actions have no key.
+func KeyByTypeAndID(a Action) (string, bool) {
+	if ka, ok := asAction[KeyedAction](a); ok {
+		return "k" + ka.Key(), true
+	}
+
+	if na, ok := a.(NamedAction); ok {
+		return "n" + strconv.Itoa(len(na.Type())) + ":" + na.Type() + na.ID(), true
+	}
+
+	return "", false
+}
+
+// Debouncer is an Executor Interface preventing duplicate Actions from
+// running concurrently, and optionally memoizing their outcome.
+type Debouncer struct {
+	ex       Interface
+	sf       *singleflight.Group
+	key      KeyFunc
+	ttl      time.Duration
+	typeTTLs map[string]time.Duration
+	errorTTL time.Duration
+	window   windowConfig
+
+	mtx     sync.Mutex
+	flights map[string]*flight
+	memos   map[string]memo
+	windows map[string]*window   // pending windows
+	running map[string]*window   // executing windows
+	lastRun map[string]time.Time // start of the last throttled execution
+}
+
+// flight tracks the callers waiting on a debounced Action.
+type flight struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	waiters   int
+	forgotten bool // set if the outcome must not be memoized
+}
+
+// memo is the memoized outcome of an Action.
+type memo struct {
+	err     error
+	val     interface{} // produced by the Action, see resultCarrier
+	expires time.Time
+}
+
+// Debounce wraps an Executor Interface, preventing duplicate Actions from
+// running concurrently, even from concurrent calls to Execute. By default,
+// duplicates are KeyedActions sharing the same Key, or NamedActions sharing the
+// same Type and ID; see DebounceKey to change this. With
+// DebounceTTL or DebounceErrorTTL, their outcome is also memoized so that
+// duplicates arriving later are not executed either. With DebounceTrailing or
+// DebounceThrottle, duplicates are collapsed across a window of time instead of
+// only while one is in-flight.
+//
+// A debounced Action is executed with a ctx detached from its callers,
+// retaining the values of the first but cancelled only once every caller
+// waiting on it has left. A caller whose own ctx is cancelled stops waiting
+// and returns its ctx's error, without affecting the other callers.
+func Debounce(e Interface, opts ...DebounceOption) *Debouncer {
+	d := &Debouncer{
+		ex:       e,
+		sf:       new(singleflight.Group),
+		key:      KeyByTypeAndID,
+		typeTTLs: make(map[string]time.Duration),
+		flights:  make(map[string]*flight),
+		memos:    make(map[string]memo),
+		windows:  make(map[string]*window),
+		running:  make(map[string]*window),
+		lastRun:  make(map[string]time.Time),
+	}
+
+	for _, opt := range opts {
+		opt(d)
 	}
+
+	return d
 }
 
-type debouncer struct {
-	ex Interface
-	sf *singleflight.Group
+// Forget discards the memoized outcome of the NamedAction with the given Type
+// and ID, if any. If such a NamedAction is in-flight, its outcome is not
+// memoized, and subsequent duplicates do not wait on it.
+func (d *Debouncer) Forget(typ, id string) {
+	d.ForgetAction(Named(typ, id, nil))
 }
 
-func (d debouncer) Execute(ctx context.Context, actions ...Action) error {
+// ForgetAction behaves like Forget, for the Action with the same key as a.
+func (d *Debouncer) ForgetAction(a Action) {
+	key, ok := d.key(a)
+	if !ok {
+		return
+	}
+
+	d.mtx.Lock()
+	delete(d.memos, key)
+	if f, ok := d.flights[key]; ok {
+		f.forgotten = true
+		delete(d.flights, key)
+	}
+	d.mtx.Unlock()
+
+	d.sf.Forget(key)
+}
+
+// Execute executes all actions on the wrapped executor, debouncing those with
+// a key.
+func (d *Debouncer) Execute(ctx context.Context, actions ...Action) error {
 	wrapped := make([]Action, len(actions))
 
 	for i, a := range actions {
+		key, ok := d.key(a)
+		if !ok {
+			wrapped[i] = actions[i]
+			continue
+		}
+
 		if na, ok := a.(NamedAction); ok {
-			wrapped[i] = debouncedAction{
-				NamedAction: na,
-				sf:          d.sf,
-			}
+			wrapped[i] = namedDebouncedAction{NamedAction: na, d: d, key: key}
 		} else {
-			wrapped[i] = actions[i]
+			wrapped[i] = debouncedAction{Action: a, d: d, key: key}
 		}
 	}
 
 	return d.ex.Execute(ctx, wrapped...)
 }
 
+// join registers a caller waiting on key, returning the flight whose ctx the
+// debounced Action should be executed with.
+func (d *Debouncer) join(ctx context.Context, key string) *flight {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	f, ok := d.flights[key]
+	if !ok {
+		fctx, cancel := context.WithCancel(detachedContext{ctx})
+		f = &flight{ctx: fctx, cancel: cancel}
+		d.flights[key] = f
+	}
+
+	f.waiters++
+	return f
+}
+
+// leave unregisters a caller waiting on key, cancelling the flight's ctx once
+// no callers remain. The cancelled flight is forgotten so that a caller
+// arriving before it returns starts a new one rather than joining it.
+func (d *Debouncer) leave(key string, f *flight) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if f.waiters--; f.waiters > 0 {
+		return
+	}
+
+	f.cancel()
+	if d.flights[key] == f {
+		delete(d.flights, key)
+		d.sf.Forget(key)
+	}
+}
+
+// recall returns the memoized outcome for key, if any and unexpired.
+func (d *Debouncer) recall(key string, now time.Time) (memo, bool) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	m, ok := d.memos[key]
+	if ok && !now.Before(m.expires) {
+		delete(d.memos, key)
+		return memo{}, false
+	}
+
+	return m, ok
+}
+
+// remember memoizes the outcome of the flight for key, if configured to.
+func (d *Debouncer) remember(key, typ string, f *flight, val interface{}, err error, now time.Time) {
+	ttl := d.ttl
+	if t, ok := d.typeTTLs[typ]; ok {
+		ttl = t
+	}
+
+	if err != nil {
+		ttl = d.errorTTL
+	}
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if ttl <= 0 || f.forgotten {
+		return
+	}
+
+	// the failure of an abandoned flight says nothing about the action
+	if err != nil && f.ctx.Err() != nil {
+		return
+	}
+
+	m := memo{err: err, val: val, expires: now.Add(ttl)}
+	d.memos[key] = m
+	time.AfterFunc(ttl, func() { d.expire(key, m) })
+}
+
+// expire discards the memoized outcome m for key, unless it has since been
+// replaced.
+func (d *Debouncer) expire(key string, m memo) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if cur, ok := d.memos[key]; ok && cur.expires.Equal(m.expires) {
+		delete(d.memos, key)
+	}
+}
+
+// debounce executes a, unless its outcome is memoized, joining any duplicates
+// with the same key.
+func (d *Debouncer) debounce(ctx context.Context, key string, a Action) error {
+	if m, ok := d.recall(key, time.Now()); ok {
+		if m.err == nil {
+			shareResult(a, m.val)
+		}
+		return m.err
+	}
+
+	if d.window.enabled() {
+		return d.schedule(ctx, key, a)
+	}
+
+	f := d.join(ctx, key)
+	defer d.leave(key, f)
+
+	// the value produced by the first caller's action, if any, is handed to
+	// the actions of every caller sharing its execution
+	ch := d.sf.DoChan(key, func() (interface{}, error) {
+		err := a.Execute(f.ctx)
+		val := resultOf(a)
+		d.remember(key, typeOf(a), f, val, err, time.Now())
+		return val, err
+	})
+
+	select {
+	case res := <-ch:
+		if res.Err == nil {
+			shareResult(a, res.Val)
+		}
+		return res.Err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 type debouncedAction struct {
-	NamedAction
-	sf *singleflight.Group
+	Action
+	d   *Debouncer
+	key string
 }
 
 func (da debouncedAction) Execute(ctx context.Context) error {
-	// all actions only return an error, so we don't care if the value is shared
-	// or not.
-	_, err, _ := da.sf.Do(da.Type()+da.ID(), func() (interface{}, error) {
-		return nil, da.NamedAction.Execute(ctx)
-	})
+	return da.d.debounce(ctx, da.key, da.Action)
+}
+
+func (da debouncedAction) Unwrap() Action { return da.Action }
 
-	return err
+type namedDebouncedAction struct {
+	NamedAction
+	d   *Debouncer
+	key string
+}
+
+func (da namedDebouncedAction) Execute(ctx context.Context) error {
+	return da.d.debounce(ctx, da.key, da.NamedAction)
+}
+
+func (da namedDebouncedAction) Unwrap() Action { return da.NamedAction }
+
+// typeOf returns the Type of a if it is a NamedAction.
+func typeOf(a Action) string {
+	if na, ok := a.(NamedAction); ok {
+		return na.Type()
+	}
+	return ""
 }
 
-var _ Interface = debouncer{}
+// detachedContext retains the values of its parent, but is never cancelled
+// and has no deadline.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (detachedContext) Done() <-chan struct{} { return nil }
+
+func (detachedContext) Err() error { return nil }
+
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
+
+var _ Interface = (*Debouncer)(nil)
@@ -2,7 +2,9 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"runtime"
+	"strconv"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -40,3 +42,293 @@ func TestDebounce(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, uint32(3), ct)
 }
+
+func TestDebounceCancel(t *testing.T) {
+	t.Parallel()
+
+	exec := Debounce(Sequential{})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	cancelled := make(chan struct{})
+
+	act := Named("slow", "1", func(ctx context.Context) error {
+		close(started)
+		select {
+		case <-release:
+			return nil
+		case <-ctx.Done():
+			close(cancelled)
+			return ctx.Err()
+		}
+	})
+
+	first, cancelFirst := context.WithCancel(context.Background())
+	firstRes := make(chan error)
+	go func() { firstRes <- exec.Execute(first, act) }()
+	<-started
+
+	second, cancelSecond := context.WithCancel(context.Background())
+	defer cancelSecond()
+	secondRes := make(chan error)
+	go func() { secondRes <- exec.Execute(second, act) }()
+
+	// wait for the second caller to join the flight
+	key, _ := KeyByTypeAndID(act)
+	for {
+		exec.mtx.Lock()
+		n := exec.flights[key].waiters
+		exec.mtx.Unlock()
+		if n == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// the first caller leaves without cancelling the shared execution
+	cancelFirst()
+	assert.True(t, errors.Is(<-firstRes, context.Canceled))
+
+	close(release)
+	assert.NoError(t, <-secondRes)
+
+	select {
+	case <-cancelled:
+		t.Fatal("shared execution was cancelled")
+	default:
+	}
+
+	t.Run("all waiters leave", func(t *testing.T) {
+		t.Parallel()
+
+		exec := Debounce(Sequential{})
+
+		cancelled := make(chan struct{})
+		act := Named("slow", "1", func(ctx context.Context) error {
+			<-ctx.Done()
+			close(cancelled)
+			return ctx.Err()
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		assert.True(t, errors.Is(exec.Execute(ctx, act), context.DeadlineExceeded))
+
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Fatal("shared execution was not cancelled")
+		}
+	})
+
+	t.Run("rejoin while cancelling", func(t *testing.T) {
+		t.Parallel()
+
+		exec := Debounce(Sequential{})
+
+		var ct uint32
+		cancelled := make(chan struct{})
+		release := make(chan struct{})
+		act := Named("slow", "1", func(ctx context.Context) error {
+			if atomic.AddUint32(&ct, 1) > 1 {
+				return nil
+			}
+			<-ctx.Done()
+			close(cancelled)
+			// slow to notice the cancellation
+			<-release
+			return ctx.Err()
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		assert.True(t, errors.Is(exec.Execute(ctx, act), context.DeadlineExceeded))
+		<-cancelled
+		defer close(release)
+
+		// a new caller starts a fresh execution instead of joining the abandoned one
+		res := make(chan error, 1)
+		go func() { res <- exec.Execute(context.Background(), act) }()
+
+		select {
+		case err := <-res:
+			assert.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("joined the abandoned execution")
+		}
+		assert.Equal(t, uint32(2), atomic.LoadUint32(&ct))
+	})
+}
+
+func TestDebounceMemoize(t *testing.T) {
+	t.Parallel()
+
+	counter := func(ct *uint32, err error) func(context.Context) error {
+		return func(context.Context) error {
+			atomic.AddUint32(ct, 1)
+			return err
+		}
+	}
+
+	t.Run("ttl", func(t *testing.T) {
+		t.Parallel()
+
+		exec := Debounce(Sequential{}, DebounceTTL(20*time.Millisecond))
+
+		var ct uint32
+		act := Named("fill", "1", counter(&ct, nil))
+
+		assert.NoError(t, exec.Execute(context.Background(), act))
+		assert.NoError(t, exec.Execute(context.Background(), act))
+		assert.Equal(t, uint32(1), atomic.LoadUint32(&ct))
+
+		time.Sleep(30 * time.Millisecond)
+
+		assert.NoError(t, exec.Execute(context.Background(), act))
+		assert.Equal(t, uint32(2), atomic.LoadUint32(&ct))
+	})
+
+	t.Run("type ttl", func(t *testing.T) {
+		t.Parallel()
+
+		exec := Debounce(Sequential{}, DebounceTTL(time.Minute), DebounceTypeTTL("volatile", 0))
+
+		var ct uint32
+		act := Named("volatile", "1", counter(&ct, nil))
+
+		assert.NoError(t, exec.Execute(context.Background(), act, act))
+		assert.Equal(t, uint32(2), atomic.LoadUint32(&ct))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		errFail := errors.New("fail")
+
+		var ct uint32
+		act := Named("fill", "1", counter(&ct, errFail))
+
+		exec := Debounce(Sequential{FailOpen: true}, DebounceTTL(time.Minute))
+		assert.Error(t, exec.Execute(context.Background(), act, act))
+		assert.Equal(t, uint32(2), atomic.LoadUint32(&ct))
+
+		exec = Debounce(Sequential{FailOpen: true}, DebounceTTL(time.Minute), DebounceErrorTTL(time.Minute))
+		err := exec.Execute(context.Background(), act, act)
+
+		var me MultiError
+		if assert.True(t, errors.As(err, &me)) && assert.Len(t, me, 2) {
+			assert.Equal(t, errFail, me[1].Err)
+		}
+		assert.Equal(t, uint32(3), atomic.LoadUint32(&ct))
+	})
+
+	t.Run("forget", func(t *testing.T) {
+		t.Parallel()
+
+		exec := Debounce(Sequential{}, DebounceTTL(time.Minute))
+
+		var ct uint32
+		act := Named("fill", "1", counter(&ct, nil))
+
+		assert.NoError(t, exec.Execute(context.Background(), act))
+		exec.Forget("fill", "2")
+		assert.NoError(t, exec.Execute(context.Background(), act))
+		assert.Equal(t, uint32(1), atomic.LoadUint32(&ct))
+
+		exec.Forget("fill", "1")
+		assert.NoError(t, exec.Execute(context.Background(), act))
+		assert.Equal(t, uint32(2), atomic.LoadUint32(&ct))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		t.Parallel()
+
+		exec := Debounce(Sequential{}, DebounceTTL(time.Millisecond))
+
+		var ct uint32
+		for i := 0; i < 100; i++ {
+			assert.NoError(t, exec.Execute(context.Background(), Named("fill", strconv.Itoa(i), counter(&ct, nil))))
+		}
+
+		time.Sleep(20 * time.Millisecond)
+
+		exec.mtx.Lock()
+		defer exec.mtx.Unlock()
+		assert.Empty(t, exec.memos)
+	})
+}
+
+func TestDebounceKey(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no collision", func(t *testing.T) {
+		t.Parallel()
+
+		ab, _ := KeyByTypeAndID(Named("ab", "c", nil))
+		a, _ := KeyByTypeAndID(Named("a", "bc", nil))
+		assert.NotEqual(t, ab, a)
+
+		named, _ := KeyByTypeAndID(Named("", "x", nil))
+		keyed, _ := KeyByTypeAndID(Keyed(ActionFunc(nil), "x"))
+		assert.NotEqual(t, named, keyed)
+
+		_, ok := KeyByTypeAndID(ActionFunc(nil))
+		assert.False(t, ok)
+	})
+
+	t.Run("keyed", func(t *testing.T) {
+		t.Parallel()
+
+		exec := Debounce(Sequential{}, DebounceTTL(time.Minute))
+
+		var ct uint32
+		act := ActionFunc(func(context.Context) error {
+			atomic.AddUint32(&ct, 1)
+			return nil
+		})
+
+		assert.NoError(t, exec.Execute(context.Background(), Keyed(act, "fill"), Keyed(act, "fill"), act, act))
+		assert.Equal(t, uint32(3), atomic.LoadUint32(&ct))
+
+		exec.ForgetAction(Keyed(act, "fill"))
+		assert.NoError(t, exec.Execute(context.Background(), Keyed(act, "fill")))
+		assert.Equal(t, uint32(4), atomic.LoadUint32(&ct))
+	})
+
+	t.Run("keyed wrapped", func(t *testing.T) {
+		t.Parallel()
+
+		exec := Metrics(Debounce(Sequential{}, DebounceTTL(time.Minute)), stubSource{})
+
+		var ct uint32
+		act := ActionFunc(func(context.Context) error {
+			atomic.AddUint32(&ct, 1)
+			return nil
+		})
+
+		assert.NoError(t, exec.Execute(context.Background(), Keyed(act, "fill"), Keyed(act, "fill")))
+		assert.Equal(t, uint32(1), atomic.LoadUint32(&ct))
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		t.Parallel()
+
+		// debounce by ID alone, regardless of Type
+		exec := Debounce(Sequential{}, DebounceTTL(time.Minute), DebounceKey(KeyByID))
+
+		var ct uint32
+		add := func(context.Context) error {
+			atomic.AddUint32(&ct, 1)
+			return nil
+		}
+
+		assert.NoError(t, exec.Execute(context.Background(), Named("foo", "1", add), Named("bar", "1", add)))
+		assert.Equal(t, uint32(1), atomic.LoadUint32(&ct))
+
+		exec.Forget("baz", "1")
+		assert.NoError(t, exec.Execute(context.Background(), Named("foo", "1", add)))
+		assert.Equal(t, uint32(2), atomic.LoadUint32(&ct))
+	})
+}
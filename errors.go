@@ -0,0 +1,169 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ActionError describes the failure of a single Action within a call to
+// Execute. The executors in this package wrap the errors returned by Actions in
+// an ActionError, identifying which Action failed.
+type ActionError struct {
+	// Index is the position of the failed Action in the actions passed to
+	// Execute.
+	Index int
+
+	// Type and ID are populated from the failed Action if it is a NamedAction.
+	Type, ID string
+
+	// Err is the error returned by the Action.
+	Err error
+}
+
+// newActionError wraps err in an ActionError describing the Action at index i.
+func newActionError(i int, a Action, err error) *ActionError {
+	ae := &ActionError{Index: i, Err: err}
+	if na, ok := a.(NamedAction); ok {
+		ae.Type, ae.ID = na.Type(), na.ID()
+	}
+
+	return ae
+}
+
+func (e *ActionError) Error() string {
+	if e.Type != "" || e.ID != "" {
+		return fmt.Sprintf("action %d (%s/%s): %v", e.Index, e.Type, e.ID, e.Err)
+	}
+
+	return fmt.Sprintf("action %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the underlying error returned by the Action.
+func (e *ActionError) Unwrap() error { return e.Err }
+
+// MultiError is returned by fail-open executors, listing every Action that
+// failed, ordered by Index.
+type MultiError []*ActionError
+
+func (m MultiError) Error() string {
+	msgs := make([]string, len(m))
+	for i, err := range m {
+		msgs[i] = err.Error()
+	}
+
+	return fmt.Sprintf("%d action(s) failed: %s", len(m), strings.Join(msgs, "; "))
+}
+
+// Is reports whether any of the contained errors matches target.
+func (m MultiError) Is(target error) bool {
+	for _, err := range m {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first contained error that matches target, and if so, sets
+// target to that error value and returns true.
+func (m MultiError) As(target interface{}) bool {
+	for _, err := range m {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// collectErrors builds a MultiError from errs, which is expected to be aligned
+// with actions. A nil error is returned if no action failed.
+func collectErrors(actions []Action, errs []error) error {
+	var m MultiError
+
+	for i, err := range errs {
+		if err != nil {
+			m = append(m, newActionError(i, actions[i], err))
+		}
+	}
+
+	if len(m) == 0 {
+		return nil
+	}
+
+	return m
+}
+
+// sortErrors orders the errors in m by Index, returning nil if m is empty.
+func sortErrors(m MultiError) error {
+	if len(m) == 0 {
+		return nil
+	}
+
+	sort.Slice(m, func(i, j int) bool { return m[i].Index < m[j].Index })
+	return m
+}
+
+// remapErrors translates the Index of the ActionErrors in err, which was
+// returned by executing a subset of a batch of actions, back to positions in
+// the original batch. The i-th Action of the subset was at position idx[i].
+func remapErrors(err error, idx []int) error {
+	remap := func(ae *ActionError) *ActionError {
+		if ae.Index < 0 || ae.Index >= len(idx) {
+			return ae
+		}
+		cp := *ae
+		cp.Index = idx[ae.Index]
+		return &cp
+	}
+
+	switch e := err.(type) {
+	case *ActionError:
+		return remap(e)
+	case MultiError:
+		m := make(MultiError, len(e))
+		for i, ae := range e {
+			m[i] = remap(ae)
+		}
+		return m
+	default:
+		return err
+	}
+}
+
+// mergeErrors combines the error returned by an executor with Actions that were
+// rejected before reaching it. If the executor failed open or succeeded, the
+// rejections are merged into a MultiError; otherwise, the executor's error is
+// returned as is.
+func mergeErrors(err error, rejected MultiError) error {
+	if len(rejected) == 0 {
+		return err
+	}
+
+	m, ok := err.(MultiError)
+	if err != nil && !ok {
+		return err
+	}
+
+	return sortErrors(append(append(MultiError{}, m...), rejected...))
+}
+
+// cancelErrors combines the errors collected by a fail-open executor before
+// its ctx was cancelled with ctx's error, attributed to the Action at index i,
+// the first not to be executed. a is nil if the Action was never received. If
+// no errors were collected, err is returned as is.
+func cancelErrors(m MultiError, i int, a Action, err error) error {
+	if len(m) == 0 {
+		return err
+	}
+
+	return sortErrors(append(m, newActionError(i, a, err)))
+}
+
+var (
+	_ error = (*ActionError)(nil)
+	_ error = MultiError(nil)
+)
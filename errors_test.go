@@ -0,0 +1,77 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActionError(t *testing.T) {
+	t.Parallel()
+
+	noop := ActionFunc(func(ctx context.Context) error { return nil })
+
+	expected := errors.New("connection refused")
+	errFn := Named("warm-user", "42", func(context.Context) error { return expected })
+
+	tests := []struct {
+		name string
+		ex   func() (Interface, CloseFunc)
+	}{
+		{"sequential", func() (Interface, CloseFunc) { return Sequential{}, func() {} }},
+		{"parallel", func() (Interface, CloseFunc) { return Parallel{}, func() {} }},
+		{"pool", func() (Interface, CloseFunc) { return Pool(0) }},
+		{"control flow", func() (Interface, CloseFunc) { return ControlFlow(Sequential{}, 1, 10), func() {} }},
+		{"debounce", func() (Interface, CloseFunc) { return Debounce(Parallel{}), func() {} }},
+		{"metrics", func() (Interface, CloseFunc) { return Metrics(Sequential{}, stubSource{}), func() {} }},
+	}
+
+	for _, test := range tests {
+		tc := test
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ex, done := tc.ex()
+			defer done()
+
+			err := ex.Execute(context.Background(), noop, errFn)
+
+			var ae *ActionError
+			if assert.True(t, errors.As(err, &ae)) {
+				assert.Equal(t, 1, ae.Index)
+				assert.Equal(t, "warm-user", ae.Type)
+				assert.Equal(t, "42", ae.ID)
+				assert.Equal(t, "action 1 (warm-user/42): connection refused", ae.Error())
+			}
+			assert.True(t, errors.Is(err, expected))
+		})
+	}
+}
+
+func TestMultiError(t *testing.T) {
+	t.Parallel()
+
+	noop := ActionFunc(func(ctx context.Context) error { return nil })
+	actions := []Action{noop, noop, noop, noop}
+
+	errA := errors.New("error a")
+	errB := &ActionError{Index: 7, Err: errors.New("error b")}
+
+	err := collectErrors(actions, []error{nil, errA, nil, errB})
+
+	assert.EqualError(t, err,
+		"2 action(s) failed: action 1: error a; action 3: action 7: error b")
+
+	assert.True(t, errors.Is(err, errA))
+	assert.True(t, errors.Is(err, errB))
+	assert.False(t, errors.Is(err, errors.New("error a")))
+
+	var ae *ActionError
+	assert.True(t, errors.As(err, &ae))
+	assert.Equal(t, 1, ae.Index)
+
+	assert.NoError(t, collectErrors(actions, []error{nil, nil, nil, nil}))
+	assert.NoError(t, collectErrors(nil, nil))
+}
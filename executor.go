@@ -1,6 +1,13 @@
 package executor
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoCapacity is returned by a TryExecutor if it cannot accept the Actions
+// without waiting.
+var ErrNoCapacity = errors.New("no capacity available")
 
 // Interface describes an executor that performs a set of Actions. It is up to
 // the implementing type to define the concurrency and open/closed failure
@@ -12,6 +19,32 @@ type Interface interface {
 	Execute(ctx context.Context, actions ...Action) error
 }
 
+// A Streamer describes an executor that performs Actions received from a
+// channel, without requiring the entire batch up front.
+type Streamer interface {
+	// ExecuteStream performs all Actions received from actions until it is
+	// closed. The index of an Action in the stream is used in place of its
+	// position in the actions passed to Execute. If ctx is cancelled after
+	// some Actions have failed open, their errors are returned in a MultiError
+	// alongside ctx's error.
+	//
+	// ExecuteStream may return before actions is closed, such as when an
+	// Action fails closed, after which the stream is no longer drained. The
+	// caller's ctx is not cancelled in that case, so callers should derive a
+	// cancellable ctx, cancel it once ExecuteStream returns, and have producers
+	// stop sending once it is done.
+	ExecuteStream(ctx context.Context, actions <-chan Action) error
+}
+
+// A TryExecutor describes an executor that can fail fast instead of waiting
+// for capacity to perform Actions.
+type TryExecutor interface {
+	// TryExecute performs all provided actions like Execute, unless capacity
+	// for them is not immediately available, in which case none are performed
+	// and ErrNoCapacity is returned.
+	TryExecute(ctx context.Context, actions ...Action) error
+}
+
 // An Action performs a single arbitrary task.
 type Action interface {
 	// Execute performs the work of an Action. This method should make a best
@@ -19,6 +52,35 @@ type Action interface {
 	Execute(ctx context.Context) error
 }
 
+// A WrappedAction describes an Action decorating another, such as those
+// created by executors like Metrics or Retry. Optional interfaces of the
+// decorated Action, like WeightedAction, KeyedAction, PrioritizedAction and
+// DependentAction, are discovered through any WrappedActions around it.
+type WrappedAction interface {
+	Action
+
+	// Unwrap returns the decorated Action.
+	Unwrap() Action
+}
+
+// asAction returns the first of a and the Actions it wraps implementing T.
+func asAction[T Action](a Action) (T, bool) {
+	for a != nil {
+		if t, ok := a.(T); ok {
+			return t, true
+		}
+
+		wa, ok := a.(WrappedAction)
+		if !ok {
+			break
+		}
+		a = wa.Unwrap()
+	}
+
+	var zero T
+	return zero, false
+}
+
 // ActionFunc permits using a standalone function as an Action.
 type ActionFunc func(context.Context) error
 
@@ -0,0 +1,293 @@
+package executor
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+type tenantKey struct{}
+
+// WithTenant returns a copy of ctx carrying the tenant key. Pools configured
+// with PoolFair and ControlFlows configured with FlowFair schedule Actions
+// fairly between the tenants of the ctx passed to Execute. Calls without a
+// tenant share the empty tenant key.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// tenantOf returns the tenant key carried by ctx, if any.
+func tenantOf(ctx context.Context) string {
+	t, _ := ctx.Value(tenantKey{}).(string)
+	return t
+}
+
+// PoolFair causes the Pool to dequeue Actions using deficit round-robin
+// between tenants, as described by WithTenant. Each round, a tenant may have
+// as many Actions dequeued as its weight; tenants missing from weights have a
+// weight of one.
+//
+// The queue of a fair Pool is unbounded, as described by ExecuteStream.
+func PoolFair(weights map[string]int) PoolOption {
+	return func(p *WorkerPool) {
+		p.store = &fairStore{drr: newDRR(weights), queues: make(map[string]*fifoStore)}
+		p.unbounded = true
+	}
+}
+
+// FlowFair causes the ControlFlow to grant its maxActions capacity using
+// deficit round-robin between the waiting tenants, as described by
+// WithTenant. Each round, a tenant may acquire as many actions as its weight;
+// tenants missing from weights have a weight of one.
+func FlowFair(weights map[string]int) FlowOption {
+	return func(f *flow) {
+		f.fair = newFairSemaphore(f.maxActions, weights)
+	}
+}
+
+// drr tracks the state of deficit round-robin scheduling between the tenants
+// with pending work. It is not concurrency-safe.
+type drr struct {
+	weights map[string]int
+	active  []string // tenants with pending work, in round-robin order
+	deficit map[string]int64
+	pos     int
+}
+
+func newDRR(weights map[string]int) *drr {
+	return &drr{
+		weights: weights,
+		deficit: make(map[string]int64),
+	}
+}
+
+// weight returns the quantum added to the deficit of tenant each round.
+func (d *drr) weight(tenant string) int64 {
+	if w := d.weights[tenant]; w > 0 {
+		return int64(w)
+	}
+	return 1
+}
+
+// activate adds tenant to the end of the round-robin. If it is the only
+// tenant, it is granted its quantum immediately; otherwise it is granted when
+// its turn arrives.
+func (d *drr) activate(tenant string) {
+	d.active = append(d.active, tenant)
+	d.deficit[tenant] = 0
+	if len(d.active) == 1 {
+		d.deficit[tenant] = d.weight(tenant)
+	}
+}
+
+// deactivate removes tenant from the round-robin. If it was the current
+// tenant, the next tenant is granted its quantum.
+func (d *drr) deactivate(tenant string) {
+	for i, t := range d.active {
+		if t != tenant {
+			continue
+		}
+
+		d.active = append(d.active[:i], d.active[i+1:]...)
+		delete(d.deficit, tenant)
+
+		switch {
+		case i < d.pos:
+			d.pos--
+		case i == d.pos && len(d.active) > 0:
+			d.pos %= len(d.active)
+			d.deficit[d.active[d.pos]] += d.weight(d.active[d.pos])
+		}
+
+		break
+	}
+
+	if len(d.active) == 0 {
+		d.pos = 0
+	}
+}
+
+// current returns the tenant whose turn it is, and whether its deficit covers
+// the cost. If not, the turn is passed to the next tenant.
+func (d *drr) current(cost func(tenant string) int64) (string, bool) {
+	t := d.active[d.pos]
+	if d.deficit[t] >= cost(t) {
+		return t, true
+	}
+
+	d.pos = (d.pos + 1) % len(d.active)
+	next := d.active[d.pos]
+	d.deficit[next] += d.weight(next)
+
+	return next, false
+}
+
+// fairStore dequeues Actions from a separate FIFO per tenant using deficit
+// round-robin, with each Action costing one.
+type fairStore struct {
+	drr    *drr
+	queues map[string]*fifoStore
+}
+
+func (s *fairStore) put(pa poolAction) {
+	q, ok := s.queues[pa.tenant]
+	if !ok {
+		q = new(fifoStore)
+		s.queues[pa.tenant] = q
+		s.drr.activate(pa.tenant)
+	}
+
+	q.put(pa)
+}
+
+func (s *fairStore) take() poolAction {
+	unit := func(string) int64 { return 1 }
+
+	for {
+		t, ok := s.drr.current(unit)
+		if !ok {
+			continue
+		}
+
+		s.drr.deficit[t]--
+
+		q := s.queues[t]
+		pa := q.take()
+
+		if len(q.actions) == 0 {
+			delete(s.queues, t)
+			s.drr.deactivate(t)
+		}
+
+		return pa
+	}
+}
+
+// fairSemaphore is a weighted semaphore that grants capacity to waiting
+// tenants using deficit round-robin, with each acquisition costing its weight.
+type fairSemaphore struct {
+	mtx     sync.Mutex
+	size    int64
+	cur     int64
+	drr     *drr
+	waiters map[string]*list.List
+}
+
+type fairWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+func newFairSemaphore(size int64, weights map[string]int) *fairSemaphore {
+	return &fairSemaphore{
+		size:    size,
+		drr:     newDRR(weights),
+		waiters: make(map[string]*list.List),
+	}
+}
+
+// acquire obtains n units of capacity for tenant, blocking until they are
+// granted or ctx is cancelled.
+func (s *fairSemaphore) acquire(ctx context.Context, tenant string, n int64) error {
+	s.mtx.Lock()
+
+	if len(s.waiters) == 0 && s.cur+n <= s.size {
+		s.cur += n
+		s.mtx.Unlock()
+		return nil
+	}
+
+	q, ok := s.waiters[tenant]
+	if !ok {
+		q = list.New()
+		s.waiters[tenant] = q
+		s.drr.activate(tenant)
+	}
+
+	w := &fairWaiter{n: n, ready: make(chan struct{})}
+	elem := q.PushBack(w)
+
+	s.mtx.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	select {
+	case <-w.ready:
+		// acquired after ctx was cancelled, so give the capacity back
+		s.cur -= n
+	default:
+		q.Remove(elem)
+		if q.Len() == 0 {
+			delete(s.waiters, tenant)
+			s.drr.deactivate(tenant)
+		}
+	}
+
+	s.grant()
+	return ctx.Err()
+}
+
+// tryAcquire obtains n units of capacity if they are available without
+// waiting behind other tenants, reporting whether they were acquired.
+func (s *fairSemaphore) tryAcquire(n int64) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if len(s.waiters) == 0 && s.cur+n <= s.size {
+		s.cur += n
+		return true
+	}
+
+	return false
+}
+
+// release returns n units of capacity, granting it to waiting tenants.
+func (s *fairSemaphore) release(n int64) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.cur -= n
+	s.grant()
+}
+
+// grant hands out available capacity to waiters. To prevent starving large
+// requests, granting stops once the waiter whose turn it is does not fit. The
+// caller must hold the lock.
+func (s *fairSemaphore) grant() {
+	head := func(tenant string) *fairWaiter {
+		return s.waiters[tenant].Front().Value.(*fairWaiter)
+	}
+	cost := func(tenant string) int64 { return head(tenant).n }
+
+	for len(s.drr.active) > 0 {
+		t, ok := s.drr.current(cost)
+		if !ok {
+			continue
+		}
+
+		w := head(t)
+		if s.cur+w.n > s.size {
+			return
+		}
+
+		s.cur += w.n
+		s.drr.deficit[t] -= w.n
+		close(w.ready)
+
+		q := s.waiters[t]
+		q.Remove(q.Front())
+		if q.Len() == 0 {
+			delete(s.waiters, t)
+			s.drr.deactivate(t)
+		}
+	}
+}
+
+var _ queueStore = (*fairStore)(nil)
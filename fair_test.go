@@ -0,0 +1,157 @@
+package executor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoolFair(t *testing.T) {
+	t.Parallel()
+
+	p := NewWorkerPool(1, PoolFair(map[string]int{"a": 2}))
+	defer p.Close()
+
+	var mtx sync.Mutex
+	var order []string
+
+	record := func(name string) ActionFunc {
+		return func(context.Context) error {
+			mtx.Lock()
+			order = append(order, name)
+			mtx.Unlock()
+			return nil
+		}
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	blocking := ActionFunc(func(context.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	wg := &sync.WaitGroup{}
+	execute := func(ctx context.Context, actions ...Action) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, p.Execute(ctx, actions...))
+		}()
+	}
+
+	execute(context.Background(), blocking)
+	<-started
+
+	execute(WithTenant(context.Background(), "a"), record("a1"), record("a2"), record("a3"), record("a4"))
+	for p.queue.len() < 4 {
+		time.Sleep(time.Millisecond)
+	}
+
+	execute(WithTenant(context.Background(), "b"), record("b1"), record("b2"))
+	for p.queue.len() < 6 {
+		time.Sleep(time.Millisecond)
+	}
+
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, []string{"a1", "a2", "b1", "a3", "a4", "b2"}, order)
+}
+
+func TestFlowFair(t *testing.T) {
+	t.Parallel()
+
+	exec := ControlFlow(Sequential{}, 10, 1, FlowFair(nil))
+	sem := exec.(flow).fair
+
+	var mtx sync.Mutex
+	var order []string
+
+	record := func(name string) ActionFunc {
+		return func(context.Context) error {
+			mtx.Lock()
+			order = append(order, name)
+			mtx.Unlock()
+			return nil
+		}
+	}
+
+	waiting := func(n int) {
+		for {
+			sem.mtx.Lock()
+			total := 0
+			for _, q := range sem.waiters {
+				total += q.Len()
+			}
+			sem.mtx.Unlock()
+
+			if total >= n {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	wg := &sync.WaitGroup{}
+	execute := func(ctx context.Context, a Action) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, exec.Execute(ctx, a))
+		}()
+	}
+
+	execute(context.Background(), ActionFunc(func(context.Context) error {
+		close(started)
+		<-release
+		return nil
+	}))
+	<-started
+
+	a := WithTenant(context.Background(), "a")
+	execute(a, record("a1"))
+	waiting(1)
+	execute(a, record("a2"))
+	waiting(2)
+	execute(a, record("a3"))
+	waiting(3)
+	execute(WithTenant(context.Background(), "b"), record("b1"))
+	waiting(4)
+
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, []string{"a1", "b1", "a2", "a3"}, order)
+
+	t.Run("cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		exec := ControlFlow(Sequential{}, 10, 1, FlowFair(nil))
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		go exec.Execute(context.Background(), ActionFunc(func(context.Context) error {
+			close(started)
+			<-release
+			return nil
+		}))
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		err := exec.Execute(WithTenant(ctx, "a"), ActionFunc(func(context.Context) error { return nil }))
+		assert.Equal(t, context.DeadlineExceeded, err)
+
+		close(release)
+		assert.NoError(t, exec.Execute(context.Background(), ActionFunc(func(context.Context) error { return nil })))
+	})
+}
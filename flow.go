@@ -10,29 +10,72 @@ import (
 type flow struct {
 	maxActions int64
 	actions    *semaphore.Weighted
+	fair       *fairSemaphore
 	calls      *semaphore.Weighted
 	ex         Interface
+	chunk      bool
 }
 
+// LimitError is returned if more actions are requested than permitted by a
+// limit, such as the maxActions of ControlFlow or the capacity of a
+// WeightedPool. Requested and Limit account for the Weight of WeightedActions.
+type LimitError struct {
+	Limit     int64
+	Requested int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("maximum %d actions allowed, %d requested", e.Limit, e.Requested)
+}
+
+// A FlowOption configures the behavior of a ControlFlow.
+type FlowOption func(*flow)
+
 // ControlFlow decorates an Executor, limiting it to a maximum concurrent
-// number of calls and actions.
-func ControlFlow(e Interface, maxCalls, maxActions int64) Interface {
-	return flow{
+// number of calls and actions. WeightedActions count their Weight against
+// maxActions. The returned Interface is also a TryExecutor.
+func ControlFlow(e Interface, maxCalls, maxActions int64, opts ...FlowOption) Interface {
+	f := flow{
 		ex:         e,
 		maxActions: maxActions,
 		calls:      semaphore.NewWeighted(maxCalls),
 		actions:    semaphore.NewWeighted(maxActions),
 	}
+
+	for _, opt := range opts {
+		opt(&f)
+	}
+
+	return f
+}
+
+// FlowChunk causes the ControlFlow to execute batches with more actions than
+// maxActions in consecutive windows of at most maxActions each, instead of
+// rejecting them with a LimitError. If the decorated Executor fails closed, no
+// further windows are executed after one fails; if it fails open, returning a
+// MultiError, the errors of all windows are collected. A WeightedAction
+// heavier than maxActions can never fit in a window, so a batch containing one
+// is rejected with an ActionError wrapping a LimitError. TryExecute does not
+// chunk batches; see its documentation.
+func FlowChunk() FlowOption {
+	return func(f *flow) { f.chunk = true }
 }
 
 // Execute attempts to acquire the semaphores for the concurrent calls and
 // actions before delegating to the decorated Executor. If Execute is called
-// with more actions than maxActions, an error is returned.
+// with more actions than maxActions, a LimitError is returned, unless the
+// ControlFlow was created with FlowChunk.
 func (f flow) Execute(ctx context.Context, actions ...Action) error {
-	qty := int64(len(actions))
+	qty := totalWeight(actions)
 
 	if qty > f.maxActions {
-		return fmt.Errorf("maximum %d actions allowed", f.maxActions)
+		if !f.chunk {
+			return &LimitError{Limit: f.maxActions, Requested: qty}
+		}
+
+		if err := checkWeights(actions, f.maxActions); err != nil {
+			return err
+		}
 	}
 
 	if err := f.calls.Acquire(ctx, 1); err != nil {
@@ -40,12 +83,111 @@ func (f flow) Execute(ctx context.Context, actions ...Action) error {
 	}
 	defer f.calls.Release(1)
 
-	if err := f.actions.Acquire(ctx, qty); err != nil {
+	if qty > f.maxActions {
+		return f.executeChunks(ctx, actions)
+	}
+
+	return f.executeWindow(ctx, actions)
+}
+
+// TryExecute behaves like Execute, except that if the semaphores for the
+// concurrent calls and actions cannot be acquired immediately, ErrNoCapacity is
+// returned instead of waiting. Since capacity for all actions must be available
+// up front, batches with more actions than maxActions are always rejected with
+// a LimitError, even if the ControlFlow was created with FlowChunk.
+func (f flow) TryExecute(ctx context.Context, actions ...Action) error {
+	qty := totalWeight(actions)
+
+	if qty > f.maxActions {
+		return &LimitError{Limit: f.maxActions, Requested: qty}
+	}
+
+	if !f.calls.TryAcquire(1) {
+		return ErrNoCapacity
+	}
+	defer f.calls.Release(1)
+
+	if !f.tryAcquire(qty) {
+		return ErrNoCapacity
+	}
+	defer f.release(qty)
+
+	return f.ex.Execute(ctx, actions...)
+}
+
+// executeChunks executes actions in windows with a combined weight of at most
+// maxActions, acquiring the actions semaphore for each in turn.
+func (f flow) executeChunks(ctx context.Context, actions []Action) error {
+	var errs MultiError
+
+	for start, end := 0, 0; start < len(actions); start = end {
+		var weight int64
+		for ; end < len(actions) && weight+weightOf(actions[end]) <= f.maxActions; end++ {
+			weight += weightOf(actions[end])
+		}
+
+		idx := make([]int, end-start)
+		for i := range idx {
+			idx[i] = start + i
+		}
+
+		err := f.executeWindow(ctx, actions[start:end])
+		if err == nil {
+			continue
+		}
+
+		err = remapErrors(err, idx)
+		m, ok := err.(MultiError)
+		if !ok {
+			return err
+		}
+		errs = append(errs, m...)
+	}
+
+	return sortErrors(errs)
+}
+
+// executeWindow executes a single window of actions once the actions semaphore
+// is acquired.
+func (f flow) executeWindow(ctx context.Context, actions []Action) error {
+	qty := totalWeight(actions)
+
+	if err := f.acquire(ctx, qty); err != nil {
 		return err
 	}
-	defer f.actions.Release(qty)
+	defer f.release(qty)
 
 	return f.ex.Execute(ctx, actions...)
 }
 
-var _ Interface = flow{}
+// acquire obtains qty of the actions semaphore, fairly between tenants if
+// configured with FlowFair.
+func (f flow) acquire(ctx context.Context, qty int64) error {
+	if f.fair != nil {
+		return f.fair.acquire(ctx, tenantOf(ctx), qty)
+	}
+	return f.actions.Acquire(ctx, qty)
+}
+
+// tryAcquire obtains qty of the actions semaphore if it is immediately
+// available, reporting whether it was acquired.
+func (f flow) tryAcquire(qty int64) bool {
+	if f.fair != nil {
+		return f.fair.tryAcquire(qty)
+	}
+	return f.actions.TryAcquire(qty)
+}
+
+// release returns qty of the actions semaphore.
+func (f flow) release(qty int64) {
+	if f.fair != nil {
+		f.fair.release(qty)
+		return
+	}
+	f.actions.Release(qty)
+}
+
+var (
+	_ Interface   = flow{}
+	_ TryExecutor = flow{}
+)
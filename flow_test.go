@@ -2,8 +2,10 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"math"
 	"runtime"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -23,7 +25,84 @@ func TestControlFlow(t *testing.T) {
 		noopAct := ActionFunc(func(ctx context.Context) error { return nil })
 
 		err := exec.Execute(context.Background(), noopAct, noopAct, noopAct)
-		assert.Error(t, err)
+
+		var le *LimitError
+		if assert.True(t, errors.As(err, &le)) {
+			assert.Equal(t, int64(2), le.Limit)
+			assert.Equal(t, int64(3), le.Requested)
+		}
+	})
+
+	t.Run("chunk", func(t *testing.T) {
+		t.Parallel()
+
+		var inFlight, maxInFlight, ct int32
+		act := ActionFunc(func(ctx context.Context) error {
+			n := atomic.AddInt32(&inFlight, 1)
+			for {
+				m := atomic.LoadInt32(&maxInFlight)
+				if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+			atomic.AddInt32(&ct, 1)
+			return nil
+		})
+
+		exec := ControlFlow(parallel, 1, 2, FlowChunk())
+
+		assert.NoError(t, exec.Execute(context.Background(), act, act, act, act, act))
+		assert.Equal(t, int32(5), ct)
+		assert.Equal(t, int32(2), maxInFlight)
+
+		// TryExecute cannot reserve capacity for every window up front
+		var le *LimitError
+		err := exec.(TryExecutor).TryExecute(context.Background(), act, act, act)
+		if assert.True(t, errors.As(err, &le)) {
+			assert.Equal(t, int64(3), le.Requested)
+		}
+		assert.Equal(t, int32(5), ct)
+	})
+
+	t.Run("chunk fail closed", func(t *testing.T) {
+		t.Parallel()
+
+		var ct int32
+		act := ActionFunc(func(ctx context.Context) error {
+			atomic.AddInt32(&ct, 1)
+			return nil
+		})
+		fail := ActionFunc(func(ctx context.Context) error { return errors.New("fail") })
+
+		exec := ControlFlow(Sequential{}, 1, 2, FlowChunk())
+
+		err := exec.Execute(context.Background(), act, act, fail, act, act)
+
+		var ae *ActionError
+		if assert.True(t, errors.As(err, &ae)) {
+			assert.Equal(t, 2, ae.Index)
+		}
+		assert.Equal(t, int32(2), ct)
+	})
+
+	t.Run("chunk fail open", func(t *testing.T) {
+		t.Parallel()
+
+		noopAct := ActionFunc(func(ctx context.Context) error { return nil })
+		fail := ActionFunc(func(ctx context.Context) error { return errors.New("fail") })
+
+		exec := ControlFlow(Sequential{FailOpen: true}, 1, 2, FlowChunk())
+
+		err := exec.Execute(context.Background(), fail, noopAct, noopAct, fail, fail)
+
+		var me MultiError
+		if assert.True(t, errors.As(err, &me)) && assert.Len(t, me, 3) {
+			assert.Equal(t, 0, me[0].Index)
+			assert.Equal(t, 3, me[1].Index)
+			assert.Equal(t, 4, me[2].Index)
+		}
 	})
 
 	t.Run("deadline on calls", func(t *testing.T) {
@@ -63,4 +142,95 @@ func TestControlFlow(t *testing.T) {
 		err := exec.Execute(ctx, ActionFunc(func(ctx context.Context) error { return nil }))
 		assert.Equal(t, context.DeadlineExceeded, err)
 	})
+
+	t.Run("try execute", func(t *testing.T) {
+		t.Parallel()
+
+		exec := ControlFlow(parallel, math.MaxInt64, 1).(TryExecutor)
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			exec.TryExecute(context.Background(), ActionFunc(func(ctx context.Context) error {
+				close(started)
+				<-release
+				return nil
+			}))
+		}()
+		<-started
+
+		noopAct := ActionFunc(func(ctx context.Context) error { return nil })
+
+		err := exec.TryExecute(context.Background(), noopAct)
+		assert.Equal(t, ErrNoCapacity, err)
+
+		close(release)
+		<-done
+
+		assert.NoError(t, exec.TryExecute(context.Background(), noopAct))
+	})
+
+	t.Run("weighted", func(t *testing.T) {
+		t.Parallel()
+
+		exec := ControlFlow(parallel, 1, 5)
+		noopAct := ActionFunc(func(ctx context.Context) error { return nil })
+
+		assert.NoError(t, exec.Execute(context.Background(), Weighted(noopAct, 4), noopAct))
+
+		err := exec.Execute(context.Background(), Weighted(noopAct, 4), noopAct, noopAct)
+
+		var le *LimitError
+		if assert.True(t, errors.As(err, &le)) {
+			assert.Equal(t, int64(5), le.Limit)
+			assert.Equal(t, int64(6), le.Requested)
+		}
+	})
+
+	t.Run("weighted wrapped", func(t *testing.T) {
+		t.Parallel()
+
+		exec := Metrics(ControlFlow(parallel, 1, 5), stubSource{})
+		noopAct := ActionFunc(func(ctx context.Context) error { return nil })
+
+		err := exec.Execute(context.Background(), Weighted(noopAct, 6))
+
+		var le *LimitError
+		if assert.True(t, errors.As(err, &le)) {
+			assert.Equal(t, int64(6), le.Requested)
+		}
+	})
+
+	t.Run("chunk weighted", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int32
+		noopAct := ActionFunc(func(ctx context.Context) error { return nil })
+		ex := ControlFlow(executorFunc(func(ctx context.Context, actions ...Action) error {
+			atomic.AddInt32(&calls, 1)
+			return Sequential{}.Execute(ctx, actions...)
+		}), 1, 4, FlowChunk())
+
+		// windows: [3], [2 1 1], [4]
+		assert.NoError(t, ex.Execute(context.Background(),
+			Weighted(noopAct, 3), Weighted(noopAct, 2), noopAct, noopAct, Weighted(noopAct, 4)))
+		assert.Equal(t, int32(3), calls)
+
+		err := ex.Execute(context.Background(), noopAct, Weighted(noopAct, 5))
+
+		var ae *ActionError
+		var le *LimitError
+		if assert.True(t, errors.As(err, &ae)) && assert.True(t, errors.As(err, &le)) {
+			assert.Equal(t, 1, ae.Index)
+			assert.Equal(t, int64(5), le.Requested)
+		}
+	})
+}
+
+type executorFunc func(ctx context.Context, actions ...Action) error
+
+func (fn executorFunc) Execute(ctx context.Context, actions ...Action) error {
+	return fn(ctx, actions...)
 }
@@ -0,0 +1,120 @@
+package executor
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Progress is a snapshot of the state of the Actions submitted via a Handle.
+type Progress struct {
+	// Queued is the number of Actions that have not yet started. If the
+	// execution has finished, these Actions were never executed.
+	Queued int
+	// Running is the number of Actions currently executing.
+	Running int
+	// Succeeded is the number of Actions that returned without error.
+	Succeeded int
+	// Failed is the number of Actions that returned an error.
+	Failed int
+}
+
+// A Handle tracks the asynchronous execution of Actions started by Submit.
+type Handle struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+
+	queued, running, succeeded, failed int64
+}
+
+// Submit performs the actions with the provided executor in a new goroutine,
+// returning immediately with a Handle to await or cancel the execution.
+func Submit(ctx context.Context, e Interface, actions ...Action) *Handle {
+	ctx, cancel := context.WithCancel(ctx)
+
+	h := &Handle{
+		cancel: cancel,
+		done:   make(chan struct{}),
+		queued: int64(len(actions)),
+	}
+
+	wrapped := make([]Action, len(actions))
+	for i, a := range actions {
+		if na, ok := a.(NamedAction); ok {
+			wrapped[i] = namedHandleAction{NamedAction: na, h: h}
+		} else {
+			wrapped[i] = handleAction{Action: a, h: h}
+		}
+	}
+
+	go func() {
+		defer close(h.done)
+		defer cancel()
+		h.err = e.Execute(ctx, wrapped...)
+	}()
+
+	return h
+}
+
+// Wait blocks until the execution has finished, returning the error from the
+// executor.
+func (h *Handle) Wait() error {
+	<-h.done
+	return h.err
+}
+
+// Done returns a channel that is closed when the execution has finished.
+func (h *Handle) Done() <-chan struct{} { return h.done }
+
+// Cancel cancels the ctx of the execution. It does not wait for the executor
+// to return.
+func (h *Handle) Cancel() { h.cancel() }
+
+// Progress returns a snapshot of the state of the submitted Actions.
+func (h *Handle) Progress() Progress {
+	return Progress{
+		Queued:    int(atomic.LoadInt64(&h.queued)),
+		Running:   int(atomic.LoadInt64(&h.running)),
+		Succeeded: int(atomic.LoadInt64(&h.succeeded)),
+		Failed:    int(atomic.LoadInt64(&h.failed)),
+	}
+}
+
+// track executes the Action, updating the progress counters of the Handle.
+func (h *Handle) track(ctx context.Context, a Action) error {
+	atomic.AddInt64(&h.queued, -1)
+	atomic.AddInt64(&h.running, 1)
+
+	err := a.Execute(ctx)
+
+	if err != nil {
+		atomic.AddInt64(&h.failed, 1)
+	} else {
+		atomic.AddInt64(&h.succeeded, 1)
+	}
+	atomic.AddInt64(&h.running, -1)
+
+	return err
+}
+
+type namedHandleAction struct {
+	NamedAction
+	h *Handle
+}
+
+func (a namedHandleAction) Execute(ctx context.Context) error {
+	return a.h.track(ctx, a.NamedAction)
+}
+
+func (a namedHandleAction) Unwrap() Action { return a.NamedAction }
+
+type handleAction struct {
+	Action
+	h *Handle
+}
+
+func (a handleAction) Execute(ctx context.Context) error {
+	return a.h.track(ctx, a.Action)
+}
+
+func (a handleAction) Unwrap() Action { return a.Action }
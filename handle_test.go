@@ -0,0 +1,72 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmit(t *testing.T) {
+	t.Parallel()
+
+	t.Run("progress", func(t *testing.T) {
+		t.Parallel()
+
+		ex, done := Pool(1, PoolFailOpen())
+		defer done()
+
+		started := make(chan struct{})
+		unblock := make(chan struct{})
+
+		blocking := ActionFunc(func(context.Context) error {
+			close(started)
+			<-unblock
+			return nil
+		})
+
+		expected := errors.New("some error")
+		errAct := ActionFunc(func(context.Context) error { return expected })
+		noop := ActionFunc(func(context.Context) error { return nil })
+
+		h := Submit(context.Background(), ex, blocking, errAct, noop)
+
+		<-started
+		p := h.Progress()
+		assert.Equal(t, 1, p.Running)
+		assert.Equal(t, 2, p.Queued)
+
+		select {
+		case <-h.Done():
+			assert.Fail(t, "handle should not be done")
+		default:
+		}
+
+		close(unblock)
+
+		err := h.Wait()
+		assert.True(t, errors.Is(err, expected))
+		assert.Equal(t, Progress{Succeeded: 2, Failed: 1}, h.Progress())
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		t.Parallel()
+
+		waitForCancel := ActionFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		h := Submit(context.Background(), Sequential{}, waitForCancel, waitForCancel)
+		h.Cancel()
+
+		<-h.Done()
+		assert.True(t, errors.Is(h.Wait(), context.Canceled))
+
+		p := h.Progress()
+		assert.Zero(t, p.Running)
+		assert.Zero(t, p.Succeeded)
+		assert.Equal(t, 2, p.Queued+p.Failed)
+	})
+}
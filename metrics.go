@@ -2,6 +2,7 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
@@ -26,9 +27,9 @@ type metrics struct {
 }
 
 // Metrics decorates the passed in executor and emits stats for all Actions
-// executed, capturing success/failure counters as well as a latency timer for
-// the each Action. If a NamedAction is passed in, per Action Type stats are
-// emitted as well.
+// executed, capturing success/failure/panic counters as well as a latency timer
+// for the each Action. If a NamedAction is passed in, per Action Type stats are
+// emitted as well. Actions shed by a decorated Pool are counted separately.
 func Metrics(e Interface, src StatSource) Interface {
 	return &metrics{
 		ex:        e,
@@ -68,6 +69,13 @@ func (a namedStatAction) Execute(ctx context.Context) error {
 	return captureMetrics(ctx, a.NamedAction, a.global, a.stats)
 }
 
+func (a namedStatAction) shed() {
+	a.global.Shed(1)
+	a.stats.Shed(1)
+}
+
+func (a namedStatAction) Unwrap() Action { return a.NamedAction }
+
 type statAction struct {
 	Action
 	global *statSet
@@ -77,31 +85,55 @@ func (a statAction) Execute(ctx context.Context) error {
 	return captureMetrics(ctx, a.Action, a.global, nil)
 }
 
+func (a statAction) shed() { a.global.Shed(1) }
+
+func (a statAction) Unwrap() Action { return a.Action }
+
 func captureMetrics(ctx context.Context, a Action, global, stats *statSet) error {
-	// execute the action, timing its latency
+	// execute the action, timing its latency. If the action panics, the panic
+	// is counted as it unwinds.
 	start := time.Now()
+	completed := false
+	defer func() {
+		if !completed {
+			emitMetrics(time.Since(start), 0, 0, 1, global, stats)
+		}
+	}()
+
 	err := a.Execute(ctx)
 	lat := time.Since(start)
-
-	// create our counter values for error/success
-	var errored, succeeded int
-	if err != nil {
+	completed = true
+
+	// create our counter values for error/success/panic. Panics recovered into
+	// a PanicError are counted separately from ordinary errors.
+	var errored, succeeded, panicked int
+	var pe *PanicError
+	switch {
+	case errors.As(err, &pe):
+		panicked = 1
+	case err != nil:
 		errored = 1
-	} else {
+	default:
 		succeeded = 1
 	}
 
+	emitMetrics(lat, succeeded, errored, panicked, global, stats)
+
+	return err
+}
+
+func emitMetrics(lat time.Duration, succeeded, errored, panicked int, global, stats *statSet) {
 	// emit the global stats
 	global.Latency(lat)
 	global.Success(succeeded)
 	global.Error(errored)
+	global.Panic(panicked)
 
 	// if there are name-scoped stats, emit those, too
 	if stats != nil {
 		stats.Latency(lat)
 		stats.Success(succeeded)
 		stats.Error(errored)
+		stats.Panic(panicked)
 	}
-
-	return err
 }
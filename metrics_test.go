@@ -45,7 +45,7 @@ func TestMetrics(t *testing.T) {
 		errFn := Named("bar", "456", func(context.Context) error { return expected })
 
 		err := ex.Execute(context.Background(), noop, errFn)
-		assert.Equal(t, expected, err)
+		assert.True(t, errors.Is(err, expected))
 
 		ss.testCounter(t, "all_actions.success", 1)
 		ss.testCounter(t, "all_actions.error", 1)
@@ -55,6 +55,35 @@ func TestMetrics(t *testing.T) {
 		ss.testCounter(t, "bar.error", 1)
 		ss.testTimer(t, "bar", 1)
 	})
+
+	t.Run("panic", func(t *testing.T) {
+		t.Parallel()
+
+		panicFn := Named("baz", "789", func(context.Context) error { panic("oh no") })
+
+		// the panic is counted whether it is recovered before or after the
+		// metrics are captured.
+		for _, ex := range []func(StatSource) Interface{
+			func(ss StatSource) Interface { return Metrics(Recover(Sequential{}), ss) },
+			func(ss StatSource) Interface { return Recover(Metrics(Sequential{}, ss)) },
+		} {
+			ss := new(fakeStatSource)
+
+			err := ex(ss).Execute(context.Background(), noop, panicFn)
+
+			var pe *PanicError
+			assert.True(t, errors.As(err, &pe))
+
+			ss.testCounter(t, "all_actions.success", 1)
+			ss.testCounter(t, "all_actions.error", 0)
+			ss.testCounter(t, "all_actions.panic", 1)
+
+			ss.testCounter(t, "baz.success", 0)
+			ss.testCounter(t, "baz.error", 0)
+			ss.testCounter(t, "baz.panic", 1)
+			ss.testTimer(t, "baz", 1)
+		}
+	})
 }
 
 type fakeStatSource struct {
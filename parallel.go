@@ -2,33 +2,156 @@ package executor
 
 import (
 	"context"
+	"runtime"
+	"sync"
 
 	"golang.org/x/sync/errgroup"
 )
 
 // Parallel is a concurrent implementation of the Executor Interface.
-type Parallel struct{}
+type Parallel struct {
+	// FailOpen causes all actions to run to completion regardless of the
+	// failure of others. Any errors are returned as a MultiError.
+	FailOpen bool
+
+	// MaxInFlight limits the number of actions executed concurrently by
+	// ExecuteStream. If less than or equal to zero, runtime.NumCPU is used.
+	MaxInFlight int
+}
 
 // Execute performs all provided actions concurrently, failing closed on the
-// first error or if ctx is cancelled.
+// first error or if ctx is cancelled. If FailOpen is set, all actions are
+// executed and their errors collected instead.
 func (p Parallel) Execute(ctx context.Context, actions ...Action) error {
 	if err := ctx.Err(); err != nil {
 		return err
 	}
 
+	if p.FailOpen {
+		return p.executeOpen(ctx, actions)
+	}
+
 	grp, ctx := errgroup.WithContext(ctx)
 
-	for _, a := range actions {
-		grp.Go(parallelFunc(ctx, a))
+	for i, a := range actions {
+		grp.Go(parallelFunc(ctx, i, a))
 	}
 
 	return grp.Wait()
 }
 
+// executeOpen performs all actions concurrently, waiting for every action to
+// return before collecting their errors.
+func (p Parallel) executeOpen(ctx context.Context, actions []Action) error {
+	errs := make([]error, len(actions))
+
+	wg := &sync.WaitGroup{}
+	wg.Add(len(actions))
+
+	for i, a := range actions {
+		go func(i int, a Action) {
+			defer wg.Done()
+			errs[i] = a.Execute(ctx)
+		}(i, a)
+	}
+
+	wg.Wait()
+
+	return collectErrors(actions, errs)
+}
+
+// ExecuteStream performs the actions received from actions concurrently, with
+// the same failure behavior as Execute. No more than MaxInFlight actions are
+// executed at once; further actions are not received until others complete.
+func (p Parallel) ExecuteStream(ctx context.Context, actions <-chan Action) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	limit := p.MaxInFlight
+	if limit <= 0 {
+		limit = runtime.NumCPU()
+	}
+
+	if p.FailOpen {
+		return p.streamOpen(ctx, actions, limit)
+	}
+
+	grp, gctx := errgroup.WithContext(ctx)
+	grp.SetLimit(limit)
+
+	for i := 0; ; i++ {
+		select {
+		case <-gctx.Done():
+			if err := grp.Wait(); err != nil {
+				return err
+			}
+			return ctx.Err()
+		case a, ok := <-actions:
+			if !ok {
+				return grp.Wait()
+			}
+			grp.Go(parallelFunc(gctx, i, a))
+		}
+	}
+}
+
+// streamOpen performs the actions received from actions concurrently, waiting
+// for every action to return before collecting their errors.
+func (p Parallel) streamOpen(ctx context.Context, actions <-chan Action, limit int) error {
+	sem := make(chan struct{}, limit)
+	wg := &sync.WaitGroup{}
+
+	var mtx sync.Mutex
+	var errs MultiError
+
+	var i int
+
+receive:
+	for ; ; i++ {
+		select {
+		case <-ctx.Done():
+			break receive
+		case sem <- struct{}{}:
+		}
+
+		select {
+		case <-ctx.Done():
+			break receive
+		case a, ok := <-actions:
+			if !ok {
+				wg.Wait()
+				return sortErrors(errs)
+			}
+
+			wg.Add(1)
+			go func(i int, a Action) {
+				defer func() { <-sem; wg.Done() }()
+				if err := a.Execute(ctx); err != nil {
+					mtx.Lock()
+					errs = append(errs, newActionError(i, a, err))
+					mtx.Unlock()
+				}
+			}(i, a)
+		}
+	}
+
+	wg.Wait()
+	return cancelErrors(errs, i, nil, ctx.Err())
+}
+
 // parallelFunc binds the Context and Action to the proper function signature for an
-// errgroup.Group.
-func parallelFunc(ctx context.Context, a Action) func() error {
-	return func() error { return a.Execute(ctx) }
+// errgroup.Group, attributing any error to the Action at index i.
+func parallelFunc(ctx context.Context, i int, a Action) func() error {
+	return func() error {
+		if err := a.Execute(ctx); err != nil {
+			return newActionError(i, a, err)
+		}
+		return nil
+	}
 }
 
-var _ Interface = Parallel{}
+var (
+	_ Interface = Parallel{}
+	_ Streamer  = Parallel{}
+)
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -63,6 +64,25 @@ func TestParallel(t *testing.T) {
 		assert.True(t, 0 == ct || 1 == ct)
 	})
 
+	t.Run("stream fail open cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		expected := errors.New("some error")
+		ctx, cancel := context.WithCancel(context.Background())
+
+		err := Parallel{FailOpen: true, MaxInFlight: 1}.ExecuteStream(ctx, streamActions(
+			ActionFunc(func(context.Context) error { return expected }),
+			ActionFunc(func(context.Context) error { cancel(); return nil }),
+			ActionFunc(func(context.Context) error { return nil })))
+
+		var me MultiError
+		if assert.True(t, errors.As(err, &me)) && assert.Len(t, me, 2) {
+			assert.Equal(t, 0, me[0].Index)
+			assert.Equal(t, expected, me[0].Err)
+		}
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+
 	t.Run("cancelled", func(t *testing.T) {
 		t.Parallel()
 
@@ -86,4 +106,88 @@ func TestParallel(t *testing.T) {
 		assert.Equal(t, context.Canceled, err)
 		assert.Zero(t, ct)
 	})
+
+	t.Run("fail open", func(t *testing.T) {
+		t.Parallel()
+
+		var ct uint32
+
+		addToCt := ActionFunc(func(ctx context.Context) error {
+			atomic.AddUint32(&ct, 1)
+			return nil
+		})
+
+		errA := errors.New("error a")
+		errB := errors.New("error b")
+
+		err := Parallel{FailOpen: true}.Execute(context.Background(),
+			ActionFunc(func(ctx context.Context) error { return errA }),
+			addToCt,
+			ActionFunc(func(ctx context.Context) error { return errB }),
+			addToCt)
+
+		var me MultiError
+		assert.True(t, errors.As(err, &me))
+		assert.Len(t, me, 2)
+		assert.Equal(t, 0, me[0].Index)
+		assert.Equal(t, 2, me[1].Index)
+		assert.True(t, errors.Is(err, errA))
+		assert.True(t, errors.Is(err, errB))
+		assert.Equal(t, uint32(2), ct)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		t.Parallel()
+
+		var ct, inFlight, maxInFlight int32
+
+		addToCt := ActionFunc(func(ctx context.Context) error {
+			n := atomic.AddInt32(&inFlight, 1)
+			for {
+				m := atomic.LoadInt32(&maxInFlight)
+				if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+			atomic.AddInt32(&ct, 1)
+			return nil
+		})
+
+		n := 20
+		ch := make(chan Action)
+		go func() {
+			defer close(ch)
+			for i := 0; i < n; i++ {
+				ch <- addToCt
+			}
+		}()
+
+		err := Parallel{MaxInFlight: 3}.ExecuteStream(context.Background(), ch)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(n), ct)
+		assert.True(t, maxInFlight <= 3)
+	})
+
+	t.Run("stream fail open", func(t *testing.T) {
+		t.Parallel()
+
+		expected := errors.New("some error")
+		errAct := ActionFunc(func(ctx context.Context) error { return expected })
+		noop := ActionFunc(func(ctx context.Context) error { return nil })
+
+		err := Parallel{FailOpen: true, MaxInFlight: 2}.ExecuteStream(context.Background(),
+			streamActions(noop, errAct, noop, errAct, noop))
+
+		var me MultiError
+		if assert.True(t, errors.As(err, &me)) && assert.Len(t, me, 2) {
+			assert.Equal(t, 1, me[0].Index)
+			assert.Equal(t, 3, me[1].Index)
+		}
+
+		err = Parallel{}.ExecuteStream(context.Background(),
+			streamActions(noop, errAct, noop))
+		assert.True(t, errors.Is(err, expected))
+	})
 }
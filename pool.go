@@ -4,96 +4,563 @@ import (
 	"context"
 	"errors"
 	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
-// A CloseFunc is returned by Pool to release resources held by a Pool. The
-// function should be called only once; subsequent calls may result in a
-// panic.
+// ErrPoolClosed is returned by a WorkerPool that is no longer accepting
+// Actions.
+var ErrPoolClosed = errors.New("pool is closed")
+
+// A CloseFunc is returned by Pool to release resources held by a Pool.
+// Calling the function more than once has no effect.
 type CloseFunc func()
 
-type pool struct {
-	done chan struct{}
-	in   chan poolAction
+// A PoolOption configures the behavior of a Pool.
+type PoolOption func(*WorkerPool)
+
+// PoolFailOpen causes the Pool to execute all Actions passed to Execute
+// regardless of the failure of others. Any errors are returned as a
+// MultiError.
+func PoolFailOpen() PoolOption {
+	return func(p *WorkerPool) { p.failOpen = true }
+}
+
+// PoolRecover causes the Pool to recover from panicking Actions, returning a
+// PanicError in their place. The worker that executed the Action is replaced.
+func PoolRecover() PoolOption {
+	return func(p *WorkerPool) { p.recover = true }
+}
+
+// DefaultAutoscaleInterval is used by PoolAutoscale if its interval is not
+// positive.
+const DefaultAutoscaleInterval = time.Second
+
+// PoolAutoscale periodically resizes the Pool between min and max workers,
+// checking its queue depth and worker utilization every interval. Workers are
+// added while Actions are queued, and removed while fewer than half are busy.
+//
+// If min is less than one, a single worker is kept. If max is less than or
+// equal to zero, runtime.NumCPU is used; if it is less than min, the Pool is
+// kept at min workers. If interval is not positive, DefaultAutoscaleInterval is
+// used.
+func PoolAutoscale(min, max int, interval time.Duration) PoolOption {
+	if min < 1 {
+		min = 1
+	}
+
+	if max <= 0 {
+		max = runtime.NumCPU()
+	}
+
+	if max < min {
+		max = min
+	}
+
+	if interval <= 0 {
+		interval = DefaultAutoscaleInterval
+	}
+
+	return func(p *WorkerPool) {
+		p.scaler = &autoscaler{min: min, max: max, interval: interval}
+	}
+}
+
+// WorkerPool is an Executor Interface backed by a concurrent worker pool. In
+// addition to executing Actions, a WorkerPool can be shut down gracefully.
+type WorkerPool struct {
+	queue     *poolQueue
+	store     queueStore
+	unbounded bool
+	failOpen  bool
+	recover   bool
+	scaler    *autoscaler
+	shedder   *shedder
+	maxQueue  int
+	backlog   int   // bounds the in-flight Actions of a stream if the queue is unbounded
+	busy      int64 // number of workers currently executing an Action
+
+	mtx     sync.Mutex
+	workers []chan struct{} // closed to stop the corresponding worker
+	closed  bool
+	calls   sync.WaitGroup // in-flight calls to Execute and ExecuteStream
+	closing chan struct{}  // closed once new Actions are no longer accepted
+	done    chan struct{}  // closed once all calls have returned, stopping the workers
+
+	killOnce sync.Once
+	killed   chan struct{} // closed to cancel all in-flight Actions
 }
 
 // Pool creates an Executor Interface instance backed by a concurrent worker
 // pool. Up to n Actions can be in-flight simultaneously; if n is less than or
 // equal to zero, runtime.NumCPU is used. The returned CloseFunc must be called
-// to release resources held by the pool.
-func Pool(n int) (Interface, CloseFunc) {
+// to release resources held by the pool. The returned Interface is also a
+// Streamer and TryExecutor.
+func Pool(n int, opts ...PoolOption) (Interface, CloseFunc) {
+	p := NewWorkerPool(n, opts...)
+	return p, p.Close
+}
+
+// NewWorkerPool creates a WorkerPool with n workers; if n is less than or equal
+// to zero, runtime.NumCPU is used. Either Close or Shutdown must be called to
+// release resources held by the pool.
+func NewWorkerPool(n int, opts ...PoolOption) *WorkerPool {
 	if n <= 0 {
 		n = runtime.NumCPU()
 	}
 
-	p := pool{done: make(chan struct{}), in: make(chan poolAction, n)}
+	p := &WorkerPool{
+		store:   new(fifoStore),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+		killed:  make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	p.backlog = n
+	if p.unbounded {
+		p.queue = newPoolQueue(0, p.store)
+	} else {
+		p.queue = newPoolQueue(n, p.store)
+	}
+	p.queue.limit = p.maxQueue
+
+	if p.scaler != nil {
+		n = p.scaler.clamp(n)
+		go p.scaler.run(p)
+	}
+
+	p.Resize(n)
 
-	for i := 0; i < n; i++ {
-		go p.work(p.in, p.done)
+	return p
+}
+
+// Resize grows or shrinks the pool to n workers; if n is less than one, a
+// single worker is kept. Removed workers exit once they finish their current
+// Action, leaving queued Actions for the remaining workers.
+func (p *WorkerPool) Resize(n int) {
+	if n < 1 {
+		n = 1
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	for len(p.workers) < n {
+		stop := make(chan struct{})
+		p.workers = append(p.workers, stop)
+		go p.work(stop)
 	}
 
-	return p, func() { close(p.done) }
+	for len(p.workers) > n {
+		last := len(p.workers) - 1
+		close(p.workers[last])
+		p.workers = p.workers[:last]
+	}
+}
+
+// Size returns the current number of workers in the pool.
+func (p *WorkerPool) Size() int {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	return len(p.workers)
 }
 
 // Execute enqueues all Actions on the worker pool, failing closed on the
 // first error or if ctx is cancelled. This method blocks until all enqueued
 // Actions have returned. In the event of an error, not all Actions may be
-// executed.
-func (p pool) Execute(ctx context.Context, actions ...Action) error {
+// executed. If the Pool fails open, all Actions are executed and their errors
+// collected instead.
+func (p *WorkerPool) Execute(ctx context.Context, actions ...Action) error {
+	return p.execute(ctx, actions, false)
+}
+
+// TryExecute behaves like Execute, except that if the pool's queue cannot
+// accept all Actions immediately, none are enqueued and ErrNoCapacity is
+// returned instead of waiting.
+func (p *WorkerPool) TryExecute(ctx context.Context, actions ...Action) error {
+	return p.execute(ctx, actions, true)
+}
+
+// execute implements Execute, and TryExecute if try is true.
+func (p *WorkerPool) execute(ctx context.Context, actions []Action, try bool) error {
 	qty := len(actions)
 	if qty == 0 {
 		return nil
 	}
 
-	ctx, cancel := context.WithCancel(ctx)
+	if !p.begin() {
+		return ErrPoolClosed
+	}
+	defer p.calls.Done()
+
+	ctx, cancel := p.context(ctx)
 	defer cancel()
 
-	res := make(chan error, qty)
+	res := make(chan poolResult, qty)
 
 	var err error
 	var queued uint64
 
-enqueue:
-	for _, action := range actions {
-		pa := poolAction{ctx: ctx, act: action, res: res}
-		select {
-		case <-p.done: // pool is closed
-			cancel()
-			return errors.New("pool is closed")
-		case <-ctx.Done(): // ctx is closed by caller
-			err = ctx.Err()
-			break enqueue
-		case p.in <- pa: // enqueue action
+	var errs []error
+	if p.failOpen {
+		errs = make([]error, qty)
+	}
+
+	if try {
+		pas := make([]poolAction, qty)
+		for i, action := range actions {
+			pas[i] = newPoolAction(ctx, i, action, res)
+		}
+
+		if !p.queue.tryPush(pas...) {
+			return ErrNoCapacity
+		}
+		queued = uint64(qty)
+	} else {
+		for i, action := range actions {
+			// enqueue action, unless ctx is closed by caller or the pool was closed
+			err = p.queue.push(ctx, newPoolAction(ctx, i, action, res))
+
+			if err == ErrShed {
+				observeShed(action)
+				if p.failOpen {
+					errs[i], err = err, nil
+					continue
+				}
+				err = newActionError(i, action, err)
+			}
+
+			if err != nil {
+				break
+			}
 			queued++
 		}
 	}
 
 	for ; queued > 0; queued-- {
-		if r := <-res; r != nil {
-			if err == nil {
-				err = r
+		if r := <-res; r.err != nil {
+			if p.failOpen {
+				errs[r.idx] = r.err
+			} else if err == nil {
+				err = newActionError(r.idx, actions[r.idx], r.err)
 				cancel()
 			}
 		}
 	}
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	return collectErrors(actions, errs)
 }
 
-func (p pool) work(in <-chan poolAction, done <-chan struct{}) {
+// ExecuteStream enqueues the actions received from actions on the worker pool,
+// with the same failure behavior as Execute. Further actions are not received
+// while the pool's queue is full, or once the pool is shut down.
+//
+// So that all waiting Actions are considered when ordering them, the queue of a
+// pool using PoolPriority or PoolFair is unbounded. Such a queue never fills,
+// so actions are instead not received while as many of this call's actions are
+// in-flight as the pool was created with workers.
+func (p *WorkerPool) ExecuteStream(ctx context.Context, actions <-chan Action) error {
+	if !p.begin() {
+		return ErrPoolClosed
+	}
+	defer p.calls.Done()
+
+	ctx, cancel := p.context(ctx)
+	defer cancel()
+
+	// an unbounded queue never blocks the producer, so unless it sheds actions
+	// instead, the in-flight actions of the stream are bounded separately.
+	var slots chan struct{}
+	if p.queue.capacity() == 0 && p.queue.limit == 0 {
+		slots = make(chan struct{}, p.backlog)
+	}
+
+	res := make(chan poolResult, p.queue.capacity())
+	wg := &sync.WaitGroup{}
+
+	var mtx sync.Mutex
+	var err error
+	var errs MultiError
+
+	go func() {
+		for r := range res {
+			if slots != nil {
+				<-slots
+			}
+
+			if r.err != nil {
+				mtx.Lock()
+				if p.failOpen {
+					errs = append(errs, newActionError(r.idx, r.act, r.err))
+				} else if err == nil {
+					err = newActionError(r.idx, r.act, r.err)
+					cancel()
+				}
+				mtx.Unlock()
+			}
+			wg.Done()
+		}
+	}()
+
+	var closed bool
+	var i int
+
+enqueue:
+	for ; ; i++ {
+		if slots != nil {
+			select {
+			case <-p.closing:
+				closed = true
+				break enqueue
+			case <-ctx.Done():
+				break enqueue
+			case slots <- struct{}{}:
+			}
+		}
+
+		select {
+		case <-p.closing: // pool is shutting down
+			closed = true
+			break enqueue
+		case <-ctx.Done():
+			break enqueue
+		case a, ok := <-actions:
+			if !ok {
+				break enqueue
+			}
+
+			wg.Add(1)
+			switch err := p.queue.push(ctx, newPoolAction(ctx, i, a, res)); err {
+			case nil:
+			case ErrShed: // report as the action's result
+				observeShed(a)
+				res <- poolResult{idx: i, act: a, err: err}
+			default:
+				wg.Done()
+				break enqueue
+			}
+		}
+	}
+
+	wg.Wait()
+	close(res)
+
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	// unless cancelled due to a failed action, ctx is only done if the caller
+	// cancelled it or the pool was closed.
+	if err = ctx.Err(); err == nil && closed {
+		err = ErrPoolClosed
+	}
+
+	if err != nil {
+		return cancelErrors(errs, i, nil, err)
+	}
+
+	return sortErrors(errs)
+}
+
+// Shutdown gracefully shuts down the pool. New Actions are no longer accepted,
+// while those already passed to Execute or ExecuteStream are permitted to
+// complete. If ctx is cancelled before then, the remaining Actions are
+// cancelled and ctx's error is returned.
+func (p *WorkerPool) Shutdown(ctx context.Context) error {
+	p.stop()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		p.kill()
+		return ctx.Err()
+	}
+}
+
+// Close immediately shuts down the pool, cancelling any in-flight Actions.
+// Calling Close more than once has no effect.
+func (p *WorkerPool) Close() {
+	p.stop()
+	p.kill()
+}
+
+// begin registers an in-flight call, reporting false if the pool is no longer
+// accepting Actions.
+func (p *WorkerPool) begin() bool {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.closed {
+		return false
+	}
+
+	p.calls.Add(1)
+	return true
+}
+
+// stop prevents new calls from being accepted. Once all in-flight calls have
+// returned, the workers are stopped.
+func (p *WorkerPool) stop() {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.closed {
+		return
+	}
+
+	p.closed = true
+	close(p.closing)
+
+	go func() {
+		p.calls.Wait()
+		close(p.done)
+	}()
+}
+
+// kill cancels all in-flight Actions.
+func (p *WorkerPool) kill() {
+	p.killOnce.Do(func() { close(p.killed) })
+}
+
+// context derives a ctx from the caller's that is also cancelled if the pool
+// is killed.
+func (p *WorkerPool) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	go func() {
+		select {
+		case <-p.killed:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
+
+func (p *WorkerPool) work(stop <-chan struct{}) {
+	var a poolAction
+
+	if p.recover {
+		defer func() {
+			if r := recover(); r != nil {
+				atomic.AddInt64(&p.busy, -1)
+
+				// report the panic in place of the action's result, then replace
+				// this worker since its loop has been unwound.
+				a.res <- poolResult{idx: a.idx, act: a.act, err: newPanicError(a.act, r)}
+				go p.work(stop)
+			}
+		}()
+	}
+
+	for {
+		var ok bool
+		if a, ok = p.queue.pop(stop, p.done); !ok {
+			return
+		}
+
+		if p.shedder != nil && p.shedder.shed(a, time.Now()) {
+			observeShed(a.act)
+			a.res <- poolResult{idx: a.idx, act: a.act, err: ErrShed}
+			continue
+		}
+
+		atomic.AddInt64(&p.busy, 1)
+		err := a.act.Execute(a.ctx)
+		atomic.AddInt64(&p.busy, -1)
+
+		a.res <- poolResult{idx: a.idx, act: a.act, err: err}
+	}
+}
+
+// autoscaler periodically resizes a WorkerPool based on its load.
+type autoscaler struct {
+	min, max int
+	interval time.Duration
+}
+
+// clamp bounds n between the min and max number of workers.
+func (s *autoscaler) clamp(n int) int {
+	if n > s.max {
+		n = s.max
+	}
+
+	if n < s.min {
+		n = s.min
+	}
+
+	return n
+}
+
+// run resizes the pool every interval until the pool is stopped.
+func (s *autoscaler) run(p *WorkerPool) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
 	for {
 		select {
-		case <-done:
+		case <-p.done:
 			return
-		case a := <-in:
-			a.res <- a.act.Execute(a.ctx)
+		case <-ticker.C:
+			size := p.Size()
+			queued := p.queue.len()
+			busy := int(atomic.LoadInt64(&p.busy))
+
+			switch {
+			case queued > 0: // grow to cover the backlog
+				p.Resize(s.clamp(size + queued))
+			case busy*2 < size: // shrink while under-utilized
+				p.Resize(s.clamp(size - 1))
+			}
 		}
 	}
 }
 
 type poolAction struct {
-	ctx context.Context
+	ctx      context.Context
+	idx      int
+	act      Action
+	res      chan<- poolResult
+	priority int
+	tenant   string
+	enqueued time.Time
+}
+
+func newPoolAction(ctx context.Context, idx int, act Action, res chan<- poolResult) poolAction {
+	return poolAction{
+		ctx:      ctx,
+		idx:      idx,
+		act:      act,
+		res:      res,
+		priority: priorityOf(ctx, act),
+		tenant:   tenantOf(ctx),
+		enqueued: time.Now(),
+	}
+}
+
+type poolResult struct {
+	idx int
 	act Action
-	res chan<- error
+	err error
 }
 
-var _ Interface = pool{}
+var (
+	_ Interface   = (*WorkerPool)(nil)
+	_ Streamer    = (*WorkerPool)(nil)
+	_ TryExecutor = (*WorkerPool)(nil)
+)
@@ -6,6 +6,7 @@ import (
 	"runtime"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -112,6 +113,357 @@ func TestPool(t *testing.T) {
 		}
 
 		err := exec.Execute(context.Background(), actions...)
-		assert.Error(t, err)
+		assert.Equal(t, ErrPoolClosed, err)
+		assert.Zero(t, ct)
+
+		assert.NotPanics(t, func() { done() })
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		t.Parallel()
+
+		p := NewWorkerPool(1)
+
+		started := make(chan struct{})
+		var ct uint32
+
+		slow := ActionFunc(func(ctx context.Context) error {
+			close(started)
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddUint32(&ct, 1)
+			return nil
+		})
+
+		addToCt := ActionFunc(func(ctx context.Context) error {
+			atomic.AddUint32(&ct, 1)
+			return nil
+		})
+
+		res := make(chan error)
+		go func() { res <- p.Execute(context.Background(), slow, addToCt, addToCt) }()
+
+		<-started
+		assert.NoError(t, p.Shutdown(context.Background()))
+		assert.NoError(t, <-res)
+		assert.Equal(t, uint32(3), ct)
+
+		assert.Equal(t, ErrPoolClosed, p.Execute(context.Background(), addToCt))
+		assert.Equal(t, ErrPoolClosed, p.ExecuteStream(context.Background(), streamActions(addToCt)))
+		assert.NoError(t, p.Shutdown(context.Background()))
+		assert.NotPanics(t, p.Close)
+	})
+
+	t.Run("shutdown deadline", func(t *testing.T) {
+		t.Parallel()
+
+		p := NewWorkerPool(1, PoolFailOpen())
+
+		started := make(chan struct{})
+		waitForCancel := ActionFunc(func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		res := make(chan error)
+		go func() { res <- p.Execute(context.Background(), waitForCancel) }()
+
+		<-started
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		assert.Equal(t, context.DeadlineExceeded, p.Shutdown(ctx))
+		assert.True(t, errors.Is(<-res, context.Canceled))
+	})
+
+	t.Run("shutdown stream", func(t *testing.T) {
+		t.Parallel()
+
+		p := NewWorkerPool(1)
+
+		ch := make(chan Action)
+		res := make(chan error)
+		go func() { res <- p.ExecuteStream(context.Background(), ch) }()
+
+		ch <- ActionFunc(func(ctx context.Context) error { return nil })
+
+		assert.NoError(t, p.Shutdown(context.Background()))
+		assert.Equal(t, ErrPoolClosed, <-res)
+	})
+
+	t.Run("fail open", func(t *testing.T) {
+		t.Parallel()
+
+		exec, done := Pool(0, PoolFailOpen())
+		defer done()
+
+		var ct uint32
+
+		addToCt := ActionFunc(func(ctx context.Context) error {
+			atomic.AddUint32(&ct, 1)
+			return nil
+		})
+
+		expected := errors.New("some error")
+		errAct := ActionFunc(func(ctx context.Context) error { return expected })
+
+		err := exec.Execute(context.Background(), errAct, addToCt, addToCt, errAct)
+
+		var me MultiError
+		assert.True(t, errors.As(err, &me))
+		assert.Len(t, me, 2)
+		assert.Equal(t, 0, me[0].Index)
+		assert.Equal(t, 3, me[1].Index)
+		assert.True(t, errors.Is(err, expected))
+		assert.Equal(t, uint32(2), ct)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		t.Parallel()
+
+		exec, done := Pool(2)
+		defer done()
+
+		var ct uint32
+
+		addToCt := ActionFunc(func(ctx context.Context) error {
+			atomic.AddUint32(&ct, 1)
+			return nil
+		})
+
+		n := 100
+		ch := make(chan Action)
+		go func() {
+			defer close(ch)
+			for i := 0; i < n; i++ {
+				ch <- addToCt
+			}
+		}()
+
+		err := exec.(Streamer).ExecuteStream(context.Background(), ch)
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(n), ct)
+	})
+
+	t.Run("stream fail open", func(t *testing.T) {
+		t.Parallel()
+
+		exec, done := Pool(0, PoolFailOpen())
+		defer done()
+
+		expected := errors.New("some error")
+		errAct := ActionFunc(func(ctx context.Context) error { return expected })
+		noop := ActionFunc(func(ctx context.Context) error { return nil })
+
+		err := exec.(Streamer).ExecuteStream(context.Background(),
+			streamActions(errAct, noop, noop, errAct))
+
+		var me MultiError
+		if assert.True(t, errors.As(err, &me)) && assert.Len(t, me, 2) {
+			assert.Equal(t, 0, me[0].Index)
+			assert.Equal(t, 3, me[1].Index)
+		}
+	})
+
+	t.Run("stream unbounded queue", func(t *testing.T) {
+		t.Parallel()
+
+		exec, done := Pool(2, PoolPriority(0))
+		defer done()
+
+		var started uint32
+		release := make(chan struct{})
+		blocking := ActionFunc(func(ctx context.Context) error {
+			atomic.AddUint32(&started, 1)
+			<-release
+			return nil
+		})
+
+		ch := make(chan Action, 10)
+		for i := 0; i < cap(ch); i++ {
+			ch <- blocking
+		}
+		close(ch)
+
+		res := make(chan error)
+		go func() { res <- exec.(Streamer).ExecuteStream(context.Background(), ch) }()
+
+		for atomic.LoadUint32(&started) < 2 {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		// no more actions are received than there are workers
+		assert.Len(t, ch, 8)
+
+		close(release)
+		assert.NoError(t, <-res)
+		assert.Equal(t, uint32(10), atomic.LoadUint32(&started))
+	})
+
+	t.Run("stream fail open cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		exec, done := Pool(1, PoolFailOpen())
+		defer done()
+
+		expected := errors.New("some error")
+		ctx, cancel := context.WithCancel(context.Background())
+
+		ch := make(chan Action)
+		go func() {
+			ch <- ActionFunc(func(context.Context) error { return expected })
+			ch <- ActionFunc(func(context.Context) error { cancel(); return nil })
+		}()
+
+		err := exec.(Streamer).ExecuteStream(ctx, ch)
+
+		var me MultiError
+		if assert.True(t, errors.As(err, &me)) && assert.Len(t, me, 2) {
+			assert.Equal(t, 0, me[0].Index)
+			assert.Equal(t, expected, me[0].Err)
+		}
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+
+	t.Run("stream cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		exec, done := Pool(0)
+		defer done()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := exec.(Streamer).ExecuteStream(ctx, make(chan Action))
+		assert.Equal(t, context.Canceled, err)
+	})
+
+	t.Run("resize", func(t *testing.T) {
+		t.Parallel()
+
+		p := NewWorkerPool(1)
+		defer p.Close()
+
+		var inFlight, maxInFlight int32
+		release := make(chan struct{})
+
+		blocking := ActionFunc(func(ctx context.Context) error {
+			n := atomic.AddInt32(&inFlight, 1)
+			for {
+				m := atomic.LoadInt32(&maxInFlight)
+				if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+					break
+				}
+			}
+			<-release
+			atomic.AddInt32(&inFlight, -1)
+			return nil
+		})
+
+		res := make(chan error)
+		go func() { res <- p.Execute(context.Background(), blocking, blocking, blocking) }()
+
+		p.Resize(3)
+		assert.Equal(t, 3, p.Size())
+
+		for atomic.LoadInt32(&inFlight) < 3 {
+			time.Sleep(time.Millisecond)
+		}
+		close(release)
+		assert.NoError(t, <-res)
+		assert.Equal(t, int32(3), maxInFlight)
+
+		p.Resize(0)
+		assert.Equal(t, 1, p.Size())
+
+		var ct uint32
+		addToCt := ActionFunc(func(ctx context.Context) error {
+			atomic.AddUint32(&ct, 1)
+			return nil
+		})
+
+		assert.NoError(t, p.Execute(context.Background(), addToCt, addToCt, addToCt, addToCt))
+		assert.Equal(t, uint32(4), ct)
+	})
+
+	t.Run("autoscale", func(t *testing.T) {
+		t.Parallel()
+
+		p := NewWorkerPool(1, PoolAutoscale(1, 4, time.Millisecond))
+		defer p.Close()
+
+		release := make(chan struct{})
+		blocking := ActionFunc(func(ctx context.Context) error {
+			<-release
+			return nil
+		})
+
+		res := make(chan error)
+		go func() { res <- p.Execute(context.Background(), blocking, blocking, blocking, blocking) }()
+
+		deadline := time.Now().Add(time.Second)
+		for p.Size() < 4 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		assert.Equal(t, 4, p.Size())
+
+		close(release)
+		assert.NoError(t, <-res)
+
+		deadline = time.Now().Add(time.Second)
+		for p.Size() > 1 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		assert.Equal(t, 1, p.Size())
+	})
+
+	t.Run("autoscale defaults", func(t *testing.T) {
+		t.Parallel()
+
+		p := NewWorkerPool(8, PoolAutoscale(0, 0, 0))
+		defer p.Close()
+
+		assert.Equal(t, 1, p.scaler.min)
+		assert.Equal(t, runtime.NumCPU(), p.scaler.max)
+		assert.Equal(t, DefaultAutoscaleInterval, p.scaler.interval)
+		assert.Equal(t, p.scaler.clamp(8), p.Size())
+
+		p = NewWorkerPool(1, PoolAutoscale(3, 2, time.Hour))
+		defer p.Close()
+
+		assert.Equal(t, 3, p.Size())
+	})
+
+	t.Run("try execute", func(t *testing.T) {
+		t.Parallel()
+
+		p := NewWorkerPool(1)
+		defer p.Close()
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		go p.Execute(context.Background(), ActionFunc(func(ctx context.Context) error {
+			close(started)
+			<-release
+			return nil
+		}))
+		<-started
+
+		var ct uint32
+		addToCt := ActionFunc(func(ctx context.Context) error {
+			atomic.AddUint32(&ct, 1)
+			return nil
+		})
+
+		// the queue holds one action while the worker is busy, so neither
+		// action of the pair is enqueued
+		err := p.TryExecute(context.Background(), addToCt, addToCt)
+		assert.Equal(t, ErrNoCapacity, err)
+		assert.Equal(t, 0, p.queue.len())
+
+		close(release)
+		assert.NoError(t, p.TryExecute(context.Background(), addToCt))
+		assert.Equal(t, uint32(1), atomic.LoadUint32(&ct))
 	})
 }
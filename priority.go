@@ -0,0 +1,48 @@
+package executor
+
+import (
+	"context"
+	"time"
+)
+
+// A PrioritizedAction describes an Action with a priority, used by a Pool
+// configured with PoolPriority to execute higher priority Actions first.
+type PrioritizedAction interface {
+	Action
+
+	// Priority returns the priority of this Action. Higher values are executed
+	// first.
+	Priority() int
+}
+
+type priorityKey struct{}
+
+// WithPriority returns a copy of ctx carrying the priority p. Actions passed to
+// a prioritized Pool with this ctx use p unless they are PrioritizedActions.
+func WithPriority(ctx context.Context, p int) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// priorityOf returns the priority of a, falling back to the priority carried
+// by ctx, if any.
+func priorityOf(ctx context.Context, a Action) int {
+	if pa, ok := asAction[PrioritizedAction](a); ok {
+		return pa.Priority()
+	}
+
+	p, _ := ctx.Value(priorityKey{}).(int)
+	return p
+}
+
+// PoolPriority causes the Pool to execute queued Actions with the highest
+// priority first, as described by PrioritizedAction or WithPriority. To
+// prevent starvation, the priority of a queued Action increases by one for
+// every aging interval it waits; an aging of zero disables this behavior.
+//
+// The queue of a prioritized Pool is unbounded, as described by ExecuteStream.
+func PoolPriority(aging time.Duration) PoolOption {
+	return func(p *WorkerPool) {
+		p.store = &priorityStore{aging: float64(aging)}
+		p.unbounded = true
+	}
+}
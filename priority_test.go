@@ -0,0 +1,104 @@
+package executor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type prioritized struct {
+	ActionFunc
+	priority int
+}
+
+func (a prioritized) Priority() int { return a.priority }
+
+func TestPoolPriority(t *testing.T) {
+	t.Parallel()
+
+	unwrapped := func(ex Interface) Interface { return ex }
+
+	run := func(t *testing.T, aging time.Duration, wrap func(Interface) Interface) []string {
+		p := NewWorkerPool(1, PoolPriority(aging))
+		defer p.Close()
+
+		ex := wrap(p)
+
+		var mtx sync.Mutex
+		var order []string
+
+		record := func(name string) ActionFunc {
+			return func(context.Context) error {
+				mtx.Lock()
+				order = append(order, name)
+				mtx.Unlock()
+				return nil
+			}
+		}
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		blocking := ActionFunc(func(context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+
+		wg := &sync.WaitGroup{}
+		execute := func(ctx context.Context, actions ...Action) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, ex.Execute(ctx, actions...))
+			}()
+		}
+
+		waitQueued := func(n int) {
+			for p.queue.len() < n {
+				time.Sleep(time.Millisecond)
+			}
+		}
+
+		execute(context.Background(), blocking)
+		<-started
+
+		execute(context.Background(), record("low 1"), record("low 2"))
+		waitQueued(2)
+		time.Sleep(time.Millisecond)
+
+		execute(WithPriority(context.Background(), 5), record("ctx 5"))
+		waitQueued(3)
+
+		execute(context.Background(), prioritized{ActionFunc: record("action 10"), priority: 10})
+		waitQueued(4)
+
+		close(release)
+		wg.Wait()
+
+		return order
+	}
+
+	t.Run("priority", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, []string{"action 10", "ctx 5", "low 1", "low 2"}, run(t, 0, unwrapped))
+	})
+
+	t.Run("wrapped", func(t *testing.T) {
+		t.Parallel()
+
+		wrap := func(ex Interface) Interface { return Metrics(ex, stubSource{}) }
+		assert.Equal(t, []string{"action 10", "ctx 5", "low 1", "low 2"}, run(t, 0, wrap))
+	})
+
+	t.Run("aging", func(t *testing.T) {
+		t.Parallel()
+
+		// with aging every nanosecond, the low priority actions have waited long
+		// enough to overtake the others.
+		assert.Equal(t, []string{"low 1", "low 2", "ctx 5", "action 10"}, run(t, time.Nanosecond, unwrapped))
+	})
+}
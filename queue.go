@@ -0,0 +1,234 @@
+package executor
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+)
+
+// poolQueue buffers the Actions enqueued on a WorkerPool. The order in which
+// Actions are dequeued is determined by its queueStore.
+type poolQueue struct {
+	slots  chan struct{} // holds a token for each enqueued Action, bounding the queue
+	notify chan struct{} // signals a waiting worker that an Action is available
+
+	mtx   sync.Mutex
+	store queueStore
+	size  int
+	limit int // if positive, pushes beyond this size are rejected with ErrShed
+}
+
+// queueStore orders the Actions in a poolQueue. Implementations need not be
+// concurrency-safe.
+type queueStore interface {
+	put(pa poolAction)
+	take() poolAction
+}
+
+// newPoolQueue creates a poolQueue holding up to size Actions. If size is less
+// than or equal to zero, the queue is unbounded.
+func newPoolQueue(size int, store queueStore) *poolQueue {
+	q := &poolQueue{
+		notify: make(chan struct{}, 1),
+		store:  store,
+	}
+
+	if size > 0 {
+		q.slots = make(chan struct{}, size)
+	}
+
+	return q
+}
+
+// push enqueues pa, blocking while the queue is full. If ctx is cancelled
+// before pa can be enqueued, its error is returned. If the queue has a limit
+// and is at it, ErrShed is returned immediately.
+func (q *poolQueue) push(ctx context.Context, pa poolAction) error {
+	if q.slots != nil {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case q.slots <- struct{}{}:
+		}
+	}
+
+	q.mtx.Lock()
+	if q.limit > 0 && q.size >= q.limit {
+		q.mtx.Unlock()
+		return ErrShed
+	}
+	q.store.put(pa)
+	q.size++
+	q.mtx.Unlock()
+
+	q.signal()
+	return nil
+}
+
+// tryPush enqueues all of pas if there is room for them without waiting,
+// reporting whether they were enqueued.
+func (q *poolQueue) tryPush(pas ...poolAction) bool {
+	var reserved int
+	release := func() {
+		for ; reserved > 0; reserved-- {
+			<-q.slots
+		}
+	}
+
+	if q.slots != nil {
+		for range pas {
+			select {
+			case q.slots <- struct{}{}:
+				reserved++
+			default:
+				release()
+				return false
+			}
+		}
+	}
+
+	q.mtx.Lock()
+	if q.limit > 0 && q.size+len(pas) > q.limit {
+		q.mtx.Unlock()
+		release()
+		return false
+	}
+
+	for _, pa := range pas {
+		q.store.put(pa)
+	}
+	q.size += len(pas)
+	q.mtx.Unlock()
+
+	q.signal()
+	return true
+}
+
+// pop dequeues the next Action, blocking until one is available. If either
+// stop or done is closed first, false is returned.
+func (q *poolQueue) pop(stop, done <-chan struct{}) (poolAction, bool) {
+	for {
+		q.mtx.Lock()
+		if q.size > 0 {
+			pa := q.store.take()
+			q.size--
+			more := q.size > 0
+			q.mtx.Unlock()
+
+			if q.slots != nil {
+				<-q.slots
+			}
+
+			// pass the signal along to another waiting worker
+			if more {
+				q.signal()
+			}
+
+			return pa, true
+		}
+		q.mtx.Unlock()
+
+		select {
+		case <-stop:
+			return poolAction{}, false
+		case <-done:
+			return poolAction{}, false
+		case <-q.notify:
+		}
+	}
+}
+
+// signal wakes a worker waiting on the queue, if any.
+func (q *poolQueue) signal() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// len returns the number of Actions currently enqueued.
+func (q *poolQueue) len() int {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	return q.size
+}
+
+// capacity returns the maximum number of Actions the queue can hold, or zero
+// if it is unbounded.
+func (q *poolQueue) capacity() int { return cap(q.slots) }
+
+// fifoStore dequeues Actions in the order they were enqueued.
+type fifoStore struct {
+	actions []poolAction
+}
+
+func (s *fifoStore) put(pa poolAction) { s.actions = append(s.actions, pa) }
+
+func (s *fifoStore) take() poolAction {
+	pa := s.actions[0]
+	s.actions[0] = poolAction{}
+	s.actions = s.actions[1:]
+	return pa
+}
+
+// priorityStore dequeues Actions with the highest priority first, breaking ties
+// in the order they were enqueued. If aging is enabled, the priority of an
+// Action increases by one for every interval it waits.
+type priorityStore struct {
+	aging float64
+	seq   uint64
+	heap  priorityHeap
+}
+
+func (s *priorityStore) put(pa poolAction) {
+	// Since all queued actions age at the same rate, their relative order never
+	// changes. Offsetting the priority by the enqueue time is sufficient.
+	score := float64(pa.priority)
+	if s.aging > 0 {
+		score -= float64(pa.enqueued.UnixNano()) / s.aging
+	}
+
+	s.seq++
+	heap.Push(&s.heap, priorityItem{pa: pa, score: score, seq: s.seq})
+}
+
+func (s *priorityStore) take() poolAction {
+	return heap.Pop(&s.heap).(priorityItem).pa
+}
+
+type priorityItem struct {
+	pa    poolAction
+	score float64
+	seq   uint64
+}
+
+type priorityHeap []priorityItem
+
+func (h priorityHeap) Len() int { return len(h) }
+
+func (h priorityHeap) Less(i, j int) bool {
+	if h[i].score != h[j].score {
+		return h[i].score > h[j].score
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h priorityHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *priorityHeap) Push(x interface{}) { *h = append(*h, x.(priorityItem)) }
+
+func (h *priorityHeap) Pop() interface{} {
+	old := *h
+	n := len(old) - 1
+	item := old[n]
+	old[n] = priorityItem{}
+	*h = old[:n]
+	return item
+}
+
+var (
+	_ queueStore     = (*fifoStore)(nil)
+	_ queueStore     = (*priorityStore)(nil)
+	_ heap.Interface = (*priorityHeap)(nil)
+)
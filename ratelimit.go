@@ -0,0 +1,196 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ErrRateLimited is returned by a non-blocking RateLimit executor for Actions
+// that could not start without exceeding the configured rates.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// Rate describes a token bucket permitting PerSecond Action starts per second,
+// with bursts of up to Burst Actions. A zero PerSecond does not limit Actions.
+type Rate struct {
+	PerSecond float64
+	// Burst is the maximum number of Actions that may start at once. If zero,
+	// 1 is used.
+	Burst int
+}
+
+// RateLimitConfig configures the behavior of the RateLimit executor.
+type RateLimitConfig struct {
+	// Global limits the starts of all Actions.
+	Global Rate
+
+	// PerType limits the starts of NamedActions, separately for each Type.
+	PerType Rate
+
+	// Types overrides PerType for specific Types.
+	Types map[string]Rate
+
+	// NonBlocking causes Actions to fail with ErrRateLimited instead of
+	// waiting for the rates to permit them to start.
+	NonBlocking bool
+}
+
+type rateLimiter struct {
+	ex     Interface
+	cfg    RateLimitConfig
+	global *rate.Limiter
+
+	mtx    sync.Mutex
+	lookup map[string]*rate.Limiter
+}
+
+// RateLimit decorates the passed in executor, limiting how often Actions may
+// start, both globally and per NamedAction Type. Actions wait for the rates to
+// permit them, unless the ctx is cancelled or the limiter is NonBlocking.
+func RateLimit(e Interface, cfg RateLimitConfig) Interface {
+	return &rateLimiter{
+		ex:     e,
+		cfg:    cfg,
+		global: newLimiter(cfg.Global),
+		lookup: make(map[string]*rate.Limiter),
+	}
+}
+
+// newLimiter creates a rate.Limiter for r, or nil if r is unlimited.
+func newLimiter(r Rate) *rate.Limiter {
+	if r.PerSecond <= 0 {
+		return nil
+	}
+
+	if r.Burst <= 0 {
+		r.Burst = 1
+	}
+
+	return rate.NewLimiter(rate.Limit(r.PerSecond), r.Burst)
+}
+
+func (rl *rateLimiter) Execute(ctx context.Context, actions ...Action) error {
+	wrapped := make([]Action, len(actions))
+
+	for i, a := range actions {
+		if na, ok := a.(NamedAction); ok {
+			wrapped[i] = namedRateLimitedAction{
+				NamedAction: na,
+				rl:          rl,
+				typ:         rl.get(na.Type()),
+			}
+		} else {
+			wrapped[i] = rateLimitedAction{
+				Action: a,
+				rl:     rl,
+			}
+		}
+	}
+
+	return rl.ex.Execute(ctx, wrapped...)
+}
+
+// get returns the shared limiter for typ, or nil if typ is unlimited.
+func (rl *rateLimiter) get(typ string) *rate.Limiter {
+	rl.mtx.Lock()
+	defer rl.mtx.Unlock()
+
+	lim, ok := rl.lookup[typ]
+	if !ok {
+		r, ok := rl.cfg.Types[typ]
+		if !ok {
+			r = rl.cfg.PerType
+		}
+
+		lim = newLimiter(r)
+		rl.lookup[typ] = lim
+	}
+
+	return lim
+}
+
+// wait reserves a token from each of the limiters, blocking until all permit
+// the Action to start. If the limiter is non-blocking or ctx is cancelled
+// before then, the reservations are returned.
+func (rl *rateLimiter) wait(ctx context.Context, limiters ...*rate.Limiter) error {
+	now := time.Now()
+	reservations := make([]*rate.Reservation, 0, len(limiters))
+
+	cancel := func(at time.Time) {
+		for _, r := range reservations {
+			r.CancelAt(at)
+		}
+	}
+
+	var delay time.Duration
+	for _, lim := range limiters {
+		if lim == nil {
+			continue
+		}
+
+		r := lim.ReserveN(now, 1)
+		if !r.OK() {
+			cancel(now)
+			return ErrRateLimited
+		}
+		reservations = append(reservations, r)
+
+		if d := r.DelayFrom(now); d > delay {
+			delay = d
+		}
+	}
+
+	if delay == 0 {
+		return nil
+	}
+
+	if rl.cfg.NonBlocking {
+		cancel(now)
+		return ErrRateLimited
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		cancel(time.Now())
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type namedRateLimitedAction struct {
+	NamedAction
+	rl  *rateLimiter
+	typ *rate.Limiter
+}
+
+func (a namedRateLimitedAction) Execute(ctx context.Context) error {
+	if err := a.rl.wait(ctx, a.rl.global, a.typ); err != nil {
+		return err
+	}
+	return a.NamedAction.Execute(ctx)
+}
+
+func (a namedRateLimitedAction) Unwrap() Action { return a.NamedAction }
+
+type rateLimitedAction struct {
+	Action
+	rl *rateLimiter
+}
+
+func (a rateLimitedAction) Execute(ctx context.Context) error {
+	if err := a.rl.wait(ctx, a.rl.global); err != nil {
+		return err
+	}
+	return a.Action.Execute(ctx)
+}
+
+func (a rateLimitedAction) Unwrap() Action { return a.Action }
+
+var _ Interface = (*rateLimiter)(nil)
@@ -0,0 +1,68 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+
+	noop := func(context.Context) error { return nil }
+
+	t.Run("global", func(t *testing.T) {
+		t.Parallel()
+
+		ex := RateLimit(Parallel{}, RateLimitConfig{
+			Global: Rate{PerSecond: 100, Burst: 1},
+		})
+
+		start := time.Now()
+		err := ex.Execute(context.Background(), ActionFunc(noop), ActionFunc(noop), ActionFunc(noop))
+
+		assert.NoError(t, err)
+		assert.True(t, time.Since(start) >= 15*time.Millisecond)
+	})
+
+	t.Run("per type", func(t *testing.T) {
+		t.Parallel()
+
+		ex := RateLimit(Sequential{FailOpen: true}, RateLimitConfig{
+			PerType:     Rate{PerSecond: 1, Burst: 1},
+			Types:       map[string]Rate{"unlimited": {}},
+			NonBlocking: true,
+		})
+
+		err := ex.Execute(context.Background(),
+			Named("foo", "1", noop),
+			Named("foo", "2", noop),
+			Named("bar", "1", noop),
+			Named("unlimited", "1", noop),
+			Named("unlimited", "2", noop),
+			ActionFunc(noop))
+
+		var me MultiError
+		if assert.True(t, errors.As(err, &me)) && assert.Len(t, me, 1) {
+			assert.Equal(t, 1, me[0].Index)
+			assert.Equal(t, ErrRateLimited, me[0].Err)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		ex := RateLimit(Sequential{}, RateLimitConfig{
+			Global: Rate{PerSecond: 0.001, Burst: 1},
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		err := ex.Execute(ctx, ActionFunc(noop), ActionFunc(noop))
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+}
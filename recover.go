@@ -0,0 +1,101 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is returned in place of a panic recovered from an Action.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value interface{}
+
+	// Stack is the stack trace of the goroutine at the time of the panic.
+	Stack []byte
+
+	// Type and ID are populated from the panicking Action if it is a
+	// NamedAction.
+	Type, ID string
+}
+
+// newPanicError captures the recovered value r, along with the current stack
+// trace. It must be called from the deferred function that recovered r.
+func newPanicError(a Action, r interface{}) *PanicError {
+	pe := &PanicError{Value: r, Stack: debug.Stack()}
+	if na, ok := a.(NamedAction); ok {
+		pe.Type, pe.ID = na.Type(), na.ID()
+	}
+
+	return pe
+}
+
+func (e *PanicError) Error() string {
+	if e.Type != "" || e.ID != "" {
+		return fmt.Sprintf("action %s/%s panicked: %v", e.Type, e.ID, e.Value)
+	}
+
+	return fmt.Sprintf("action panicked: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Recover decorates the passed in executor, converting panics from Actions
+// into a PanicError returned by the Action.
+func Recover(e Interface) Interface {
+	return recoverer{ex: e}
+}
+
+type recoverer struct {
+	ex Interface
+}
+
+func (r recoverer) Execute(ctx context.Context, actions ...Action) error {
+	wrapped := make([]Action, len(actions))
+
+	for i, a := range actions {
+		if na, ok := a.(NamedAction); ok {
+			wrapped[i] = namedRecoverAction{NamedAction: na}
+		} else {
+			wrapped[i] = recoverAction{Action: a}
+		}
+	}
+
+	return r.ex.Execute(ctx, wrapped...)
+}
+
+type namedRecoverAction struct {
+	NamedAction
+}
+
+func (a namedRecoverAction) Execute(ctx context.Context) error {
+	return executeRecover(ctx, a.NamedAction)
+}
+
+func (a namedRecoverAction) Unwrap() Action { return a.NamedAction }
+
+type recoverAction struct {
+	Action
+}
+
+func (a recoverAction) Execute(ctx context.Context) error {
+	return executeRecover(ctx, a.Action)
+}
+
+func (a recoverAction) Unwrap() Action { return a.Action }
+
+func executeRecover(ctx context.Context, a Action) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = newPanicError(a, r)
+		}
+	}()
+
+	return a.Execute(ctx)
+}
+
+var _ Interface = recoverer{}
@@ -0,0 +1,68 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecover(t *testing.T) {
+	t.Parallel()
+
+	expected := errors.New("some error")
+
+	t.Run("named", func(t *testing.T) {
+		t.Parallel()
+
+		ex := Recover(Parallel{})
+
+		err := ex.Execute(context.Background(),
+			Named("foo", "123", func(context.Context) error { panic(expected) }))
+
+		var pe *PanicError
+		if assert.True(t, errors.As(err, &pe)) {
+			assert.Equal(t, expected, pe.Value)
+			assert.Equal(t, "foo", pe.Type)
+			assert.Equal(t, "123", pe.ID)
+			assert.Contains(t, string(pe.Stack), "recover_test.go")
+			assert.Equal(t, "action foo/123 panicked: some error", pe.Error())
+		}
+		assert.True(t, errors.Is(err, expected))
+	})
+
+	t.Run("unnamed", func(t *testing.T) {
+		t.Parallel()
+
+		ex := Recover(Sequential{})
+
+		err := ex.Execute(context.Background(),
+			ActionFunc(func(context.Context) error { panic("oh no") }))
+
+		var pe *PanicError
+		if assert.True(t, errors.As(err, &pe)) {
+			assert.Equal(t, "oh no", pe.Value)
+			assert.Equal(t, "action panicked: oh no", pe.Error())
+		}
+	})
+
+	t.Run("pool", func(t *testing.T) {
+		t.Parallel()
+
+		ex, done := Pool(1, PoolRecover())
+		defer done()
+
+		panicAct := ActionFunc(func(context.Context) error { panic("oh no") })
+		noop := ActionFunc(func(context.Context) error { return nil })
+
+		for i := 0; i < 3; i++ {
+			err := ex.Execute(context.Background(), panicAct)
+
+			var pe *PanicError
+			assert.True(t, errors.As(err, &pe))
+		}
+
+		assert.NoError(t, ex.Execute(context.Background(), noop, noop))
+	})
+}
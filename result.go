@@ -0,0 +1,161 @@
+package executor
+
+import (
+	"context"
+	"sync"
+)
+
+// A ResultAction performs a single arbitrary task, producing a value of type T.
+type ResultAction[T any] interface {
+	// Execute performs the work of the ResultAction. This method should make a
+	// best effort to be cancelled if the provided ctx is cancelled.
+	Execute(ctx context.Context) (T, error)
+}
+
+// ResultFunc permits using a standalone function as a ResultAction.
+type ResultFunc[T any] func(context.Context) (T, error)
+
+// Execute satisfies the ResultAction interface, delegating the call to the
+// underlying function.
+func (fn ResultFunc[T]) Execute(ctx context.Context) (T, error) { return fn(ctx) }
+
+type namedResult[T any] struct {
+	ResultFunc[T]
+	typ, id string
+}
+
+func (a namedResult[T]) Type() string { return a.typ }
+
+func (a namedResult[T]) ID() string { return a.id }
+
+// NamedResult creates a ResultAction with the specified Type and ID. When
+// passed to Collect, the ResultAction is executed as a NamedAction.
+func NamedResult[T any](typ, id string, fn ResultFunc[T]) ResultAction[T] {
+	return namedResult[T]{
+		ResultFunc: fn,
+		typ:        typ,
+		id:         id,
+	}
+}
+
+// Collect performs the actions with the provided executor, returning their
+// results in the same order as the actions. The results of Actions that failed
+// or were not executed are the zero value of T. If a ResultAction also has
+// Type and ID methods, it is executed as a NamedAction. Duplicates collapsed by
+// a Debouncer each receive the value of the shared execution.
+func Collect[T any](ctx context.Context, e Interface, actions ...ResultAction[T]) ([]T, error) {
+	cells := make([]resultCell[T], len(actions))
+	wrapped := make([]Action, len(actions))
+
+	for i, a := range actions {
+		ra := resultAction[T]{act: a, dst: &cells[i]}
+
+		if n, ok := a.(interface {
+			Type() string
+			ID() string
+		}); ok {
+			wrapped[i] = namedResultAction[T]{
+				resultAction: ra,
+				typ:          n.Type(),
+				id:           n.ID(),
+			}
+		} else {
+			wrapped[i] = ra
+		}
+	}
+
+	err := e.Execute(ctx, wrapped...)
+
+	// actions abandoned by e may still be running, so their cells are read
+	// rather than writing to results directly
+	results := make([]T, len(actions))
+	for i := range cells {
+		results[i] = cells[i].get()
+	}
+
+	return results, err
+}
+
+// resultCell holds the value produced by a ResultAction, which may be set after
+// Collect has returned.
+type resultCell[T any] struct {
+	mtx sync.Mutex
+	v   T
+}
+
+func (c *resultCell[T]) get() T {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.v
+}
+
+func (c *resultCell[T]) set(v T) {
+	c.mtx.Lock()
+	c.v = v
+	c.mtx.Unlock()
+}
+
+// resultCarrier is implemented by Actions that produce a value, permitting
+// executors that share one execution among duplicate Actions, like a
+// Debouncer, to hand that value to each of them.
+type resultCarrier interface {
+	Action
+	result() interface{}
+	setResult(v interface{})
+}
+
+// resultOf returns the value produced by a, if it or an Action it wraps is a
+// resultCarrier.
+func resultOf(a Action) interface{} {
+	if rc, ok := asAction[resultCarrier](a); ok {
+		return rc.result()
+	}
+	return nil
+}
+
+// shareResult hands v, produced by a duplicate of a, to a if it or an Action it
+// wraps is a resultCarrier.
+func shareResult(a Action, v interface{}) {
+	if rc, ok := asAction[resultCarrier](a); ok {
+		rc.setResult(v)
+	}
+}
+
+// resultAction adapts a ResultAction to an Action, storing its result in dst.
+type resultAction[T any] struct {
+	act ResultAction[T]
+	dst *resultCell[T]
+}
+
+func (a resultAction[T]) Execute(ctx context.Context) error {
+	v, err := a.act.Execute(ctx)
+	if err == nil {
+		a.dst.set(v)
+	}
+	return err
+}
+
+func (a resultAction[T]) result() interface{} { return a.dst.get() }
+
+// setResult ignores values of another type, produced by a duplicate from a
+// different call to Collect.
+func (a resultAction[T]) setResult(v interface{}) {
+	if t, ok := v.(T); ok {
+		a.dst.set(t)
+	}
+}
+
+type namedResultAction[T any] struct {
+	resultAction[T]
+	typ, id string
+}
+
+func (a namedResultAction[T]) Type() string { return a.typ }
+
+func (a namedResultAction[T]) ID() string { return a.id }
+
+var (
+	_ ResultAction[int] = ResultFunc[int](nil)
+	_ resultCarrier     = resultAction[int]{}
+	_ NamedAction       = namedResultAction[int]{}
+)
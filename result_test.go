@@ -0,0 +1,119 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollect(t *testing.T) {
+	t.Parallel()
+
+	square := func(n int) ResultFunc[int] {
+		return func(context.Context) (int, error) { return n * n, nil }
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ex, done := Pool(0)
+		defer done()
+
+		n := 100
+		actions := make([]ResultAction[int], n)
+		for i := 0; i < n; i++ {
+			actions[i] = square(i)
+		}
+
+		results, err := Collect(context.Background(), ex, actions...)
+		assert.NoError(t, err)
+		if assert.Len(t, results, n) {
+			for i, r := range results {
+				assert.Equal(t, i*i, r)
+			}
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		expected := errors.New("some error")
+
+		results, err := Collect[int](context.Background(), Parallel{FailOpen: true},
+			square(2),
+			NamedResult("foo", "123", func(context.Context) (int, error) { return 1, expected }),
+			square(3))
+
+		var ae *ActionError
+		if assert.True(t, errors.As(err, &ae)) {
+			assert.Equal(t, 1, ae.Index)
+			assert.Equal(t, "foo", ae.Type)
+			assert.Equal(t, "123", ae.ID)
+		}
+		assert.Equal(t, []int{4, 0, 9}, results)
+	})
+
+	t.Run("debounce", func(t *testing.T) {
+		t.Parallel()
+
+		ex := Debounce(Parallel{}, DebounceTTL(time.Hour))
+
+		var ct uint32
+		release := make(chan struct{})
+		action := func(n int) ResultAction[int] {
+			return NamedResult("foo", "123", func(context.Context) (int, error) {
+				atomic.AddUint32(&ct, 1)
+				<-release
+				return n, nil
+			})
+		}
+
+		res := make(chan []int, 2)
+		for i := 1; i <= 2; i++ {
+			go func(n int) {
+				results, err := Collect[int](context.Background(), ex, action(n), square(n))
+				assert.NoError(t, err)
+				res <- results
+			}(i)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+
+		a, b := <-res, <-res
+		assert.Equal(t, a[0], b[0], "duplicates should share the value")
+		assert.NotZero(t, a[0])
+		assert.ElementsMatch(t, []int{1, 4}, []int{a[1], b[1]},
+			"other actions should not be affected")
+
+		// memoized
+		results, err := Collect(context.Background(), ex, action(3))
+		assert.NoError(t, err)
+		assert.Equal(t, []int{a[0]}, results)
+		assert.Equal(t, uint32(1), atomic.LoadUint32(&ct))
+	})
+
+	t.Run("abandoned", func(t *testing.T) {
+		t.Parallel()
+
+		ex := Debounce(Parallel{})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		done := make(chan struct{})
+		results, err := Collect(ctx, ex, NamedResult("foo", "123", func(context.Context) (int, error) {
+			defer close(done)
+			time.Sleep(20 * time.Millisecond)
+			return 1, nil
+		}))
+
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		<-done
+		assert.Equal(t, []int{0}, results, "a late result should not be written")
+	})
+}
@@ -0,0 +1,225 @@
+package executor
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Backoff computes the delay before retrying a failed Action. The attempt is
+// the number of times the Action has been executed so far, and prev is the
+// delay returned for the previous retry, or zero for the first.
+type Backoff func(attempt int, prev time.Duration) time.Duration
+
+// ConstantBackoff waits the same duration d between each attempt.
+func ConstantBackoff(d time.Duration) Backoff {
+	return func(int, time.Duration) time.Duration { return d }
+}
+
+// ExponentialBackoff doubles the delay between each attempt, starting at base
+// and never exceeding max.
+func ExponentialBackoff(base, max time.Duration) Backoff {
+	return func(attempt int, _ time.Duration) time.Duration {
+		d := base
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+
+		if d > max {
+			return max
+		}
+		return d
+	}
+}
+
+// DecorrelatedJitterBackoff picks a random delay between base and three times
+// the previous delay, never exceeding max. This spreads out the retries of
+// Actions that failed at the same time.
+func DecorrelatedJitterBackoff(base, max time.Duration) Backoff {
+	return func(_ int, prev time.Duration) time.Duration {
+		if prev < base {
+			prev = base
+		}
+
+		d := base
+		if spread := int64(prev*3 - base); spread > 0 {
+			d += time.Duration(rand.Int63n(spread))
+		}
+
+		if d > max {
+			return max
+		}
+		return d
+	}
+}
+
+// DefaultMaxAttempts is used by a RetryPolicy that does not specify
+// MaxAttempts.
+const DefaultMaxAttempts = 3
+
+// RetryPolicy configures the behavior of the Retry executor.
+type RetryPolicy struct {
+	// MaxAttempts caps the number of times an Action is executed, including
+	// the first attempt. If zero, DefaultMaxAttempts is used.
+	MaxAttempts int
+
+	// Backoff computes the delay between attempts. If nil, failed Actions are
+	// retried immediately.
+	Backoff Backoff
+
+	// Retryable reports whether an Action that returned err should be retried.
+	// If nil, all errors are retried.
+	Retryable func(err error) bool
+
+	// Stats, if provided, receives a counter for each retry, both for all
+	// Actions and per NamedAction Type.
+	Stats StatSource
+}
+
+type retrier struct {
+	ex       Interface
+	policy   RetryPolicy
+	counters *retryCounters
+}
+
+// Retry decorates the passed in executor, re-executing individual Actions that
+// fail according to the provided policy. Retries stop as soon as the ctx is
+// cancelled, returning the most recent error from the Action.
+func Retry(e Interface, policy RetryPolicy) Interface {
+	if policy.MaxAttempts == 0 {
+		policy.MaxAttempts = DefaultMaxAttempts
+	}
+
+	if policy.Backoff == nil {
+		policy.Backoff = ConstantBackoff(0)
+	}
+
+	if policy.Retryable == nil {
+		policy.Retryable = func(error) bool { return true }
+	}
+
+	r := &retrier{ex: e, policy: policy}
+	if policy.Stats != nil {
+		r.counters = &retryCounters{
+			src:    policy.Stats,
+			lookup: make(map[string]Counter),
+		}
+	}
+
+	return r
+}
+
+func (r *retrier) Execute(ctx context.Context, actions ...Action) error {
+	wrapped := make([]Action, len(actions))
+	global := r.counter("all_actions")
+
+	for i, a := range actions {
+		if na, ok := a.(NamedAction); ok {
+			wrapped[i] = namedRetryAction{
+				NamedAction: na,
+				r:           r,
+				global:      global,
+				stats:       r.counter(na.Type()),
+			}
+		} else {
+			wrapped[i] = retryAction{
+				Action: a,
+				r:      r,
+				global: global,
+			}
+		}
+	}
+
+	return r.ex.Execute(ctx, wrapped...)
+}
+
+// counter returns the retry Counter for name, or nil if the policy has no
+// StatSource.
+func (r *retrier) counter(name string) Counter {
+	if r.counters == nil {
+		return nil
+	}
+	return r.counters.get(name)
+}
+
+// retryCounters lazily creates the "<name>.retry" Counters of a retrier. Unlike
+// a statCache, it creates no other metrics, so the same StatSource may also be
+// used by Metrics.
+type retryCounters struct {
+	src    StatSource
+	mtx    sync.Mutex
+	lookup map[string]Counter
+}
+
+func (rc *retryCounters) get(name string) Counter {
+	rc.mtx.Lock()
+	defer rc.mtx.Unlock()
+
+	c, ok := rc.lookup[name]
+	if !ok {
+		c = rc.src.Counter(name + ".retry")
+		rc.lookup[name] = c
+	}
+
+	return c
+}
+
+// retry executes the Action until it succeeds, returns a non-retryable error,
+// exhausts its attempts, or ctx is cancelled.
+func (r *retrier) retry(ctx context.Context, a Action, global, stats Counter) error {
+	var delay time.Duration
+
+	for attempt := 1; ; attempt++ {
+		err := a.Execute(ctx)
+		if err == nil ||
+			attempt >= r.policy.MaxAttempts ||
+			ctx.Err() != nil ||
+			!r.policy.Retryable(err) {
+			return err
+		}
+
+		delay = r.policy.Backoff(attempt, delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		for _, c := range [...]Counter{global, stats} {
+			if c != nil {
+				c(1)
+			}
+		}
+	}
+}
+
+type namedRetryAction struct {
+	NamedAction
+	r      *retrier
+	global Counter
+	stats  Counter
+}
+
+func (a namedRetryAction) Execute(ctx context.Context) error {
+	return a.r.retry(ctx, a.NamedAction, a.global, a.stats)
+}
+
+func (a namedRetryAction) Unwrap() Action { return a.NamedAction }
+
+type retryAction struct {
+	Action
+	r      *retrier
+	global Counter
+}
+
+func (a retryAction) Execute(ctx context.Context) error {
+	return a.r.retry(ctx, a.Action, a.global, nil)
+}
+
+func (a retryAction) Unwrap() Action { return a.Action }
+
+var _ Interface = (*retrier)(nil)
@@ -0,0 +1,165 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+
+	expected := errors.New("some error")
+
+	failN := func(n uint32, ct *uint32) ActionFunc {
+		return func(ctx context.Context) error {
+			if atomic.AddUint32(ct, 1) <= n {
+				return expected
+			}
+			return nil
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ss := new(fakeStatSource)
+		ex := Retry(Parallel{}, RetryPolicy{Stats: ss})
+
+		var named, unnamed uint32
+		err := ex.Execute(context.Background(),
+			Named("foo", "123", failN(2, &named)),
+			failN(1, &unnamed))
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(3), named)
+		assert.Equal(t, uint32(2), unnamed)
+
+		ss.testCounter(t, "all_actions.retry", 3)
+		ss.testCounter(t, "foo.retry", 2)
+	})
+
+	t.Run("shared stats", func(t *testing.T) {
+		t.Parallel()
+
+		ss := &uniqueStatSource{t: t}
+		ex := Metrics(Retry(Sequential{}, RetryPolicy{Stats: ss}), ss)
+
+		var ct uint32
+		assert.NoError(t, ex.Execute(context.Background(), Named("foo", "123", failN(1, &ct))))
+
+		ss.testCounter(t, "foo.retry", 1)
+		ss.testCounter(t, "foo.success", 1)
+	})
+
+	t.Run("max attempts", func(t *testing.T) {
+		t.Parallel()
+
+		ex := Retry(Sequential{}, RetryPolicy{MaxAttempts: 2})
+
+		var ct uint32
+		err := ex.Execute(context.Background(), failN(5, &ct))
+
+		assert.True(t, errors.Is(err, expected))
+		assert.Equal(t, uint32(2), ct)
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		t.Parallel()
+
+		ex := Retry(Sequential{}, RetryPolicy{
+			Retryable: func(err error) bool { return !errors.Is(err, expected) },
+		})
+
+		var ct uint32
+		err := ex.Execute(context.Background(), failN(5, &ct))
+
+		assert.True(t, errors.Is(err, expected))
+		assert.Equal(t, uint32(1), ct)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		ex := Retry(Sequential{}, RetryPolicy{
+			MaxAttempts: 100,
+			Backoff:     ConstantBackoff(time.Hour),
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		var ct uint32
+		err := ex.Execute(ctx, failN(100, &ct))
+
+		assert.True(t, errors.Is(err, expected))
+		assert.Equal(t, uint32(1), ct)
+	})
+}
+
+// uniqueStatSource fails the test if a metric is created more than once, like
+// registries that reject duplicates.
+type uniqueStatSource struct {
+	fakeStatSource
+	t     *testing.T
+	names sync.Map
+}
+
+func (s *uniqueStatSource) register(name string) {
+	if _, dupe := s.names.LoadOrStore(name, struct{}{}); dupe {
+		s.t.Errorf("metric %q created more than once", name)
+	}
+}
+
+func (s *uniqueStatSource) Timer(name string) Timer {
+	s.register("timer " + name)
+	return s.fakeStatSource.Timer(name)
+}
+
+func (s *uniqueStatSource) Counter(name string) Counter {
+	s.register("counter " + name)
+	return s.fakeStatSource.Counter(name)
+}
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("constant", func(t *testing.T) {
+		t.Parallel()
+
+		b := ConstantBackoff(time.Second)
+		assert.Equal(t, time.Second, b(1, 0))
+		assert.Equal(t, time.Second, b(10, time.Second))
+	})
+
+	t.Run("exponential", func(t *testing.T) {
+		t.Parallel()
+
+		b := ExponentialBackoff(time.Millisecond, 10*time.Millisecond)
+		assert.Equal(t, time.Millisecond, b(1, 0))
+		assert.Equal(t, 2*time.Millisecond, b(2, 0))
+		assert.Equal(t, 8*time.Millisecond, b(4, 0))
+		assert.Equal(t, 10*time.Millisecond, b(5, 0))
+		assert.Equal(t, 10*time.Millisecond, b(1000, 0))
+	})
+
+	t.Run("decorrelated jitter", func(t *testing.T) {
+		t.Parallel()
+
+		base, max := time.Millisecond, time.Second
+		b := DecorrelatedJitterBackoff(base, max)
+
+		var prev time.Duration
+		for i := 1; i < 100; i++ {
+			d := b(i, prev)
+			assert.True(t, d >= base && d <= max, "%v out of bounds", d)
+			assert.True(t, prev == 0 || d <= prev*3, "%v exceeds 3x %v", d, prev)
+			prev = d
+		}
+	})
+}
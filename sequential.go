@@ -3,23 +3,66 @@ package executor
 import "context"
 
 // Sequential implements the Executor Interface, performing each Action in series.
-type Sequential struct{}
+type Sequential struct {
+	// FailOpen causes all actions to be performed regardless of the failure of
+	// earlier actions. Any errors are returned as a MultiError.
+	FailOpen bool
+}
 
 // Execute performs each action in order, exiting on the first error or if the
-// context is cancelled/deadlined.
-func (Sequential) Execute(ctx context.Context, actions ...Action) error {
-	for _, a := range actions {
+// context is cancelled/deadlined. If FailOpen is set, an error does not prevent
+// subsequent actions from being performed; if ctx is cancelled after some have
+// failed, their errors are returned along with ctx's error.
+func (s Sequential) Execute(ctx context.Context, actions ...Action) error {
+	var errs MultiError
+
+	for i, a := range actions {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return cancelErrors(errs, i, a, ctx.Err())
 		default:
 			if err := a.Execute(ctx); err != nil {
-				return err
+				if !s.FailOpen {
+					return newActionError(i, a, err)
+				}
+				errs = append(errs, newActionError(i, a, err))
 			}
 		}
 	}
 
-	return nil
+	return sortErrors(errs)
+}
+
+// ExecuteStream performs each action received from actions in order, with the
+// same failure behavior as Execute. It returns as soon as an action fails,
+// unless FailOpen is set, or once ctx is cancelled.
+func (s Sequential) ExecuteStream(ctx context.Context, actions <-chan Action) error {
+	var errs MultiError
+
+	for i := 0; ; i++ {
+		if err := ctx.Err(); err != nil {
+			return cancelErrors(errs, i, nil, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return cancelErrors(errs, i, nil, ctx.Err())
+		case a, ok := <-actions:
+			if !ok {
+				return sortErrors(errs)
+			}
+
+			if err := a.Execute(ctx); err != nil {
+				if !s.FailOpen {
+					return newActionError(i, a, err)
+				}
+				errs = append(errs, newActionError(i, a, err))
+			}
+		}
+	}
 }
 
-var _ Interface = Sequential{}
+var (
+	_ Interface = Sequential{}
+	_ Streamer  = Sequential{}
+)
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -75,4 +76,141 @@ func TestSequential(t *testing.T) {
 		assert.Equal(t, context.Canceled, err)
 		assert.Zero(t, ct)
 	})
+
+	t.Run("fail open", func(t *testing.T) {
+		t.Parallel()
+
+		ct := 0
+
+		addToCt := ActionFunc(func(ctx context.Context) error {
+			ct++
+			return nil
+		})
+
+		expected := errors.New("some error")
+
+		actions := []Action{
+			addToCt,
+			ActionFunc(func(ctx context.Context) error { return expected }),
+			addToCt,
+		}
+
+		err := Sequential{FailOpen: true}.Execute(context.Background(), actions...)
+
+		var me MultiError
+		assert.True(t, errors.As(err, &me))
+		assert.Len(t, me, 1)
+		assert.Equal(t, 1, me[0].Index)
+		assert.True(t, errors.Is(err, expected))
+		assert.Equal(t, 2, ct)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		t.Parallel()
+
+		buf := new(bytes.Buffer)
+		expected := errors.New("some error")
+
+		actions := make([]Action, 5)
+		for i := range actions {
+			x := i
+			actions[i] = ActionFunc(func(ctx context.Context) error {
+				fmt.Fprint(buf, x)
+				if x%2 == 1 {
+					return expected
+				}
+				return nil
+			})
+		}
+
+		err := Sequential{FailOpen: true}.ExecuteStream(context.Background(), streamActions(actions...))
+
+		var me MultiError
+		if assert.True(t, errors.As(err, &me)) && assert.Len(t, me, 2) {
+			assert.Equal(t, 1, me[0].Index)
+			assert.Equal(t, 3, me[1].Index)
+		}
+		assert.Equal(t, "01234", buf.String())
+
+		buf.Reset()
+		err = seq.ExecuteStream(context.Background(), streamActions(actions...))
+
+		var ae *ActionError
+		if assert.True(t, errors.As(err, &ae)) {
+			assert.Equal(t, 1, ae.Index)
+		}
+		assert.Equal(t, "01", buf.String())
+	})
+
+	t.Run("fail open cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		expected := errors.New("some error")
+
+		for _, execute := range []func(context.Context, ...Action) error{
+			Sequential{FailOpen: true}.Execute,
+			func(ctx context.Context, actions ...Action) error {
+				return Sequential{FailOpen: true}.ExecuteStream(ctx, streamActions(actions...))
+			},
+		} {
+			ctx, cancel := context.WithCancel(context.Background())
+
+			err := execute(ctx,
+				ActionFunc(func(context.Context) error { return expected }),
+				ActionFunc(func(context.Context) error { cancel(); return nil }),
+				ActionFunc(func(context.Context) error { return nil }))
+
+			var me MultiError
+			if assert.True(t, errors.As(err, &me)) && assert.Len(t, me, 2) {
+				assert.Equal(t, 0, me[0].Index)
+				assert.Equal(t, expected, me[0].Err)
+				assert.Equal(t, 2, me[1].Index)
+				assert.Equal(t, context.Canceled, me[1].Err)
+			}
+		}
+	})
+
+	t.Run("stream producer", func(t *testing.T) {
+		t.Parallel()
+
+		expected := errors.New("some error")
+		fail := ActionFunc(func(context.Context) error { return expected })
+
+		// the stream stops draining once an action fails closed, so the
+		// producer must stop sending once the ctx is cancelled.
+		ctx, cancel := context.WithCancel(context.Background())
+
+		ch := make(chan Action)
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case ch <- fail:
+				}
+			}
+		}()
+
+		err := seq.ExecuteStream(ctx, ch)
+		cancel()
+
+		assert.True(t, errors.Is(err, expected))
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("producer was not stopped")
+		}
+	})
+}
+
+// streamActions sends the actions on a closed, buffered channel.
+func streamActions(actions ...Action) <-chan Action {
+	ch := make(chan Action, len(actions))
+	for _, a := range actions {
+		ch <- a
+	}
+	close(ch)
+	return ch
 }
@@ -0,0 +1,211 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// A KeyFunc derives the key of an Action, reporting false if the Action has
+// no key.
+type KeyFunc func(a Action) (key string, ok bool)
+
+// KeyByID is a KeyFunc keying NamedActions by their ID. Actions that are not
+// NamedActions have no key.
+func KeyByID(a Action) (string, bool) {
+	na, ok := a.(NamedAction)
+	if !ok {
+		return "", false
+	}
+	return na.ID(), true
+}
+
+type serializer struct {
+	ex  Interface
+	key KeyFunc
+
+	mtx    sync.Mutex
+	queues map[string][]serialTask // per key, the running Action and those waiting behind it
+}
+
+// Serialize decorates the passed in executor, executing Actions that share a
+// key strictly one after another, in the order they were passed to Execute,
+// even across concurrent calls. Actions with different keys, or without a key,
+// may still execute concurrently. If key is nil, KeyByID is used.
+//
+// Rather than blocking the wrapped executor, an Action waiting on another with
+// the same key is only passed to it once its predecessor has returned. Each
+// Action is passed to the wrapped executor via its own concurrent call, so its
+// Execute method must be safe for concurrent use. Every Action is executed,
+// regardless of the failure of others; all errors are returned as a
+// MultiError. Actions reaching the front of their key after ctx is cancelled
+// are failed with its error instead.
+func Serialize(e Interface, key KeyFunc) Interface {
+	if key == nil {
+		key = KeyByID
+	}
+
+	return &serializer{
+		ex:     e,
+		key:    key,
+		queues: make(map[string][]serialTask),
+	}
+}
+
+func (s *serializer) Execute(ctx context.Context, actions ...Action) error {
+	c := &serialCall{
+		s:       s,
+		ctx:     ctx,
+		actions: actions,
+		keys:    make([]*string, len(actions)),
+		done:    make([]bool, len(actions)),
+		errs:    make([]error, len(actions)),
+	}
+	c.wg.Add(len(actions))
+
+	var ready []int
+
+	s.mtx.Lock()
+	for i, a := range actions {
+		k, ok := s.key(a)
+		if !ok {
+			ready = append(ready, i)
+			continue
+		}
+
+		c.keys[i] = &k
+		q := s.queues[k]
+		s.queues[k] = append(q, serialTask{call: c, idx: i})
+		if len(q) == 0 {
+			ready = append(ready, i)
+		}
+	}
+	s.mtx.Unlock()
+
+	for _, i := range ready {
+		c.submit(i)
+	}
+	c.wg.Wait()
+
+	return collectErrors(actions, c.errs)
+}
+
+// advance removes the completed Action at the front of the queue for key,
+// submitting the next one, if any.
+func (s *serializer) advance(key string) {
+	s.mtx.Lock()
+
+	q := s.queues[key][1:]
+	if len(q) == 0 {
+		delete(s.queues, key)
+		s.mtx.Unlock()
+		return
+	}
+
+	s.queues[key] = q
+	next := q[0]
+	s.mtx.Unlock()
+
+	next.call.submit(next.idx)
+}
+
+type serialTask struct {
+	call *serialCall
+	idx  int
+}
+
+// serialCall tracks the progress of a single call to Serialize's Execute.
+type serialCall struct {
+	s       *serializer
+	ctx     context.Context
+	actions []Action
+	keys    []*string // nil for Actions without a key
+	wg      sync.WaitGroup
+
+	mtx  sync.Mutex
+	done []bool
+	errs []error
+}
+
+// submit executes the Action at index i on the wrapped executor in a new
+// goroutine, so that the failure of one Action never prevents another from
+// executing. If the wrapped executor returns without executing the Action, it
+// is failed with the executor's error, or ErrSkipped if there was none.
+func (c *serialCall) submit(i int) {
+	if err := c.ctx.Err(); err != nil {
+		c.complete(i, err)
+		return
+	}
+
+	var wrapped Action
+	if na, ok := c.actions[i].(NamedAction); ok {
+		wrapped = namedSerialAction{NamedAction: na, c: c, idx: i}
+	} else {
+		wrapped = serialAction{Action: c.actions[i], c: c, idx: i}
+	}
+
+	go func() {
+		// if the action was executed, it has already been completed
+		err := c.s.ex.Execute(c.ctx, wrapped)
+
+		var ae *ActionError
+		switch {
+		case err == nil:
+			err = ErrSkipped
+		case errors.As(err, &ae):
+			err = ae.Err
+		}
+
+		c.complete(i, err)
+	}()
+}
+
+// complete records the result of the Action at index i, permitting the next
+// Action with the same key to be executed. If the Action was already
+// completed, this is a no-op.
+func (c *serialCall) complete(i int, err error) {
+	c.mtx.Lock()
+	if c.done[i] {
+		c.mtx.Unlock()
+		return
+	}
+	c.done[i] = true
+	c.errs[i] = err
+	c.mtx.Unlock()
+
+	if c.keys[i] != nil {
+		c.s.advance(*c.keys[i])
+	}
+
+	c.wg.Done()
+}
+
+type namedSerialAction struct {
+	NamedAction
+	c   *serialCall
+	idx int
+}
+
+func (a namedSerialAction) Execute(ctx context.Context) error {
+	err := a.NamedAction.Execute(ctx)
+	a.c.complete(a.idx, err)
+	return err
+}
+
+func (a namedSerialAction) Unwrap() Action { return a.NamedAction }
+
+type serialAction struct {
+	Action
+	c   *serialCall
+	idx int
+}
+
+func (a serialAction) Execute(ctx context.Context) error {
+	err := a.Action.Execute(ctx)
+	a.c.complete(a.idx, err)
+	return err
+}
+
+func (a serialAction) Unwrap() Action { return a.Action }
+
+var _ Interface = (*serializer)(nil)
@@ -0,0 +1,164 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSerialize(t *testing.T) {
+	t.Parallel()
+
+	t.Run("same key", func(t *testing.T) {
+		t.Parallel()
+
+		exec := Serialize(Parallel{}, nil)
+
+		var mtx sync.Mutex
+		var order []int
+		var inFlight, maxInFlight int32
+
+		actions := make([]Action, 5)
+		for i := range actions {
+			i := i
+			actions[i] = Named("ledger", "acct", func(context.Context) error {
+				n := atomic.AddInt32(&inFlight, 1)
+				for {
+					m := atomic.LoadInt32(&maxInFlight)
+					if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+						break
+					}
+				}
+
+				time.Sleep(time.Millisecond)
+
+				mtx.Lock()
+				order = append(order, i)
+				mtx.Unlock()
+
+				atomic.AddInt32(&inFlight, -1)
+				return nil
+			})
+		}
+
+		assert.NoError(t, exec.Execute(context.Background(), actions...))
+		assert.Equal(t, []int{0, 1, 2, 3, 4}, order)
+		assert.Equal(t, int32(1), maxInFlight)
+	})
+
+	t.Run("different keys", func(t *testing.T) {
+		t.Parallel()
+
+		exec := Serialize(Parallel{}, nil)
+
+		// each action waits for the other to start, so they must run concurrently
+		wg := &sync.WaitGroup{}
+		wg.Add(2)
+		barrier := func(context.Context) error {
+			wg.Done()
+			wg.Wait()
+			return nil
+		}
+
+		assert.NoError(t, exec.Execute(context.Background(),
+			Named("ledger", "a", barrier),
+			Named("ledger", "b", barrier)))
+	})
+
+	t.Run("across calls", func(t *testing.T) {
+		t.Parallel()
+
+		exec := Serialize(Parallel{}, nil)
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		var first int32
+
+		res := make(chan error)
+		go func() {
+			res <- exec.Execute(context.Background(), Named("ledger", "acct", func(context.Context) error {
+				close(started)
+				<-release
+				atomic.StoreInt32(&first, 1)
+				return nil
+			}))
+		}()
+		<-started
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			close(release)
+		}()
+
+		assert.NoError(t, exec.Execute(context.Background(), Named("ledger", "acct", func(context.Context) error {
+			assert.Equal(t, int32(1), atomic.LoadInt32(&first))
+			return nil
+		})))
+		assert.NoError(t, <-res)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		exec := Serialize(Sequential{}, nil)
+
+		var ct uint32
+		noop := func(context.Context) error {
+			atomic.AddUint32(&ct, 1)
+			return nil
+		}
+		fail := func(context.Context) error { return errors.New("fail") }
+
+		err := exec.Execute(context.Background(),
+			Named("ledger", "acct", fail),
+			Named("ledger", "acct", noop),
+			ActionFunc(noop))
+
+		var me MultiError
+		if assert.True(t, errors.As(err, &me)) && assert.Len(t, me, 1) {
+			assert.Equal(t, 0, me[0].Index)
+		}
+		assert.Equal(t, uint32(2), ct)
+	})
+
+	t.Run("custom key", func(t *testing.T) {
+		t.Parallel()
+
+		var inFlight, maxInFlight int32
+		act := func() Action {
+			return ActionFunc(func(context.Context) error {
+				n := atomic.AddInt32(&inFlight, 1)
+				for {
+					m := atomic.LoadInt32(&maxInFlight)
+					if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				atomic.AddInt32(&inFlight, -1)
+				return nil
+			})
+		}
+
+		keys := make(map[Action]string)
+		actions := make([]Action, 4)
+		for i := range actions {
+			a := &struct{ Action }{act()}
+			actions[i] = a
+			keys[a] = "same"
+		}
+
+		exec := Serialize(Parallel{}, func(a Action) (string, bool) {
+			k, ok := keys[a]
+			return k, ok
+		})
+
+		assert.NoError(t, exec.Execute(context.Background(), actions...))
+		assert.Equal(t, int32(1), maxInFlight)
+	})
+}
@@ -0,0 +1,63 @@
+package executor
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrShed is returned by a Pool for Actions dropped without being executed,
+// either because its queue was full or because they could no longer complete
+// in time.
+var ErrShed = errors.New("action shed")
+
+// PoolShed causes the Pool to drop Actions with ErrShed instead of executing
+// them if they waited in its queue longer than maxWait, or if less than
+// minRemaining is left before their ctx's deadline. Actions whose deadline has
+// already passed are always dropped. A zero maxWait permits any wait.
+func PoolShed(maxWait, minRemaining time.Duration) PoolOption {
+	return func(p *WorkerPool) {
+		p.shedder = &shedder{maxWait: maxWait, minRemaining: minRemaining}
+	}
+}
+
+// PoolMaxQueue causes the Pool to reject Actions with ErrShed while n Actions
+// are already queued, instead of waiting for room in its queue.
+func PoolMaxQueue(n int) PoolOption {
+	return func(p *WorkerPool) {
+		p.maxQueue = n
+		p.unbounded = true
+	}
+}
+
+// shedder decides whether a dequeued Action should be dropped.
+type shedder struct {
+	maxWait      time.Duration
+	minRemaining time.Duration
+}
+
+func (s *shedder) shed(pa poolAction, now time.Time) bool {
+	if s.maxWait > 0 && now.Sub(pa.enqueued) > s.maxWait {
+		return true
+	}
+
+	if dl, ok := pa.ctx.Deadline(); ok && dl.Sub(now) < s.minRemaining {
+		return true
+	}
+
+	return false
+}
+
+// shedObserver is implemented by Actions that track when they are shed, such
+// as those wrapped by Metrics.
+type shedObserver interface {
+	Action
+	shed()
+}
+
+// observeShed notifies a of being shed, if it or an Action it wraps is a
+// shedObserver.
+func observeShed(a Action) {
+	if o, ok := asAction[shedObserver](a); ok {
+		o.shed()
+	}
+}
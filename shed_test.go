@@ -0,0 +1,160 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoolShed(t *testing.T) {
+	t.Parallel()
+
+	noop := func(context.Context) error { return nil }
+
+	// block occupies the pool's only worker until the returned func is called.
+	block := func(ex Interface) func() {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		done := make(chan struct{})
+
+		go func() {
+			defer close(done)
+			ex.Execute(context.Background(), ActionFunc(func(context.Context) error {
+				close(started)
+				<-release
+				return nil
+			}))
+		}()
+
+		<-started
+		return func() {
+			close(release)
+			<-done
+		}
+	}
+
+	t.Run("max wait", func(t *testing.T) {
+		t.Parallel()
+
+		ex, done := Pool(1, PoolFailOpen(), PoolShed(5*time.Millisecond, 0))
+		defer done()
+
+		slow := ActionFunc(func(context.Context) error {
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		})
+
+		err := ex.Execute(context.Background(), slow, ActionFunc(noop))
+
+		var me MultiError
+		if assert.True(t, errors.As(err, &me)) && assert.Len(t, me, 1) {
+			assert.Equal(t, 1, me[0].Index)
+			assert.Equal(t, ErrShed, me[0].Err)
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		t.Parallel()
+
+		ex, done := Pool(1, PoolShed(0, time.Second))
+		defer done()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		ran := false
+		err := ex.Execute(ctx, ActionFunc(func(context.Context) error {
+			ran = true
+			return nil
+		}))
+
+		assert.True(t, errors.Is(err, ErrShed))
+		assert.False(t, ran)
+	})
+
+	maxQueue := func(t *testing.T, wrap func(Interface) Interface) {
+		t.Parallel()
+
+		ss := new(fakeStatSource)
+		p, done := Pool(1, PoolFailOpen(), PoolMaxQueue(1))
+		defer done()
+		ex := Metrics(wrap(p), ss)
+
+		release := block(p)
+
+		res := make(chan error)
+		go func() {
+			res <- ex.Execute(context.Background(), Named("foo", "1", noop), Named("foo", "2", noop))
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+		release()
+
+		var me MultiError
+		if assert.True(t, errors.As(<-res, &me)) && assert.Len(t, me, 1) {
+			assert.Equal(t, 1, me[0].Index)
+			assert.Equal(t, ErrShed, me[0].Err)
+		}
+
+		ss.testCounter(t, "all_actions.success", 1)
+		ss.testCounter(t, "all_actions.shed", 1)
+		ss.testCounter(t, "foo.shed", 1)
+	}
+
+	t.Run("max queue", func(t *testing.T) {
+		maxQueue(t, func(ex Interface) Interface { return ex })
+	})
+
+	t.Run("max queue decorated", func(t *testing.T) {
+		maxQueue(t, func(ex Interface) Interface { return Recover(ex) })
+	})
+
+	t.Run("max queue fail closed", func(t *testing.T) {
+		t.Parallel()
+
+		p := NewWorkerPool(1, PoolMaxQueue(1))
+		defer p.Close()
+
+		release := block(p)
+		defer release()
+
+		go p.Execute(context.Background(), ActionFunc(noop))
+		for p.queue.len() < 1 {
+			time.Sleep(time.Millisecond)
+		}
+
+		err := p.Execute(context.Background(), ActionFunc(noop))
+
+		var ae *ActionError
+		if assert.True(t, errors.As(err, &ae)) {
+			assert.Equal(t, 0, ae.Index)
+			assert.Equal(t, ErrShed, ae.Err)
+		}
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		t.Parallel()
+
+		p := NewWorkerPool(1, PoolFailOpen(), PoolMaxQueue(1))
+		defer p.Close()
+
+		release := block(p)
+
+		res := make(chan error)
+		go func() {
+			res <- p.ExecuteStream(context.Background(), streamActions(ActionFunc(noop), ActionFunc(noop)))
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+		release()
+
+		var me MultiError
+		if assert.True(t, errors.As(<-res, &me)) && assert.Len(t, me, 1) {
+			assert.Equal(t, 1, me[0].Index)
+			assert.Equal(t, ErrShed, me[0].Err)
+		}
+	})
+}
@@ -12,6 +12,10 @@ type statSet struct {
 	Success Counter
 	// Error is incremented when an Action results in an error
 	Error Counter
+	// Panic is incremented when an Action panics
+	Panic Counter
+	// Shed is incremented when an Action is dropped without being executed
+	Shed Counter
 }
 
 // newStatSet creates a statSet from the given src with the provided name.
@@ -20,6 +24,8 @@ func newStatSet(src StatSource, name string) *statSet {
 		Latency: src.Timer(name),
 		Success: src.Counter(name + ".success"),
 		Error:   src.Counter(name + ".error"),
+		Panic:   src.Counter(name + ".panic"),
+		Shed:    src.Counter(name + ".shed"),
 	}
 }
 
@@ -0,0 +1,185 @@
+package executor
+
+import (
+	"context"
+	"time"
+)
+
+// DebounceTrailing causes the Debouncer to delay Actions until no duplicates
+// have been submitted for the quiet period, then execute only the most
+// recently submitted one. All callers waiting on the collapsed
+// submissions receive its outcome. If maxWait is positive, the execution is
+// forced once maxWait has elapsed since the first submission, even if
+// duplicates continue to arrive.
+func DebounceTrailing(quiet, maxWait time.Duration) DebounceOption {
+	return func(d *Debouncer) {
+		d.window = windowConfig{quiet: quiet, maxWait: maxWait}
+	}
+}
+
+// DebounceThrottle causes the Debouncer to start at most one execution of
+// duplicate Actions per interval. An Action is executed immediately if no
+// duplicate started within the last interval; otherwise, it is delayed until
+// the interval has elapsed, collapsing with any duplicates submitted in the
+// meantime into a single execution of the most recent one.
+func DebounceThrottle(interval time.Duration) DebounceOption {
+	return func(d *Debouncer) {
+		d.window = windowConfig{interval: interval}
+	}
+}
+
+// windowConfig describes when the pending window of submissions of an Action
+// is executed. If both quiet and interval are zero, Actions are not windowed.
+type windowConfig struct {
+	quiet    time.Duration
+	maxWait  time.Duration
+	interval time.Duration
+}
+
+func (c windowConfig) enabled() bool { return c.quiet > 0 || c.interval > 0 }
+
+// delay returns how long from now until w should be executed. For throttled
+// windows, last is when the previous window for the same key was executed.
+func (c windowConfig) delay(w *window, last, now time.Time) time.Duration {
+	if c.interval > 0 {
+		return last.Add(c.interval).Sub(now)
+	}
+
+	d := c.quiet
+	if c.maxWait > 0 {
+		if deadline := w.first.Add(c.maxWait); now.Add(d).After(deadline) {
+			d = deadline.Sub(now)
+		}
+	}
+
+	return d
+}
+
+// window collects the duplicate submissions of an Action until it is
+// executed.
+type window struct {
+	flight
+	action Action // the most recent submission
+	first  time.Time
+	timer  *time.Timer
+	due    bool          // set if the window fired while a duplicate was still executing
+	done   chan struct{} // closed once the action has returned
+	val    interface{}   // produced by the action, see resultCarrier
+	err    error
+}
+
+// schedule adds a to the pending window for key, waiting for the window to be
+// executed or ctx to be cancelled.
+func (d *Debouncer) schedule(ctx context.Context, key string, a Action) error {
+	now := time.Now()
+
+	d.mtx.Lock()
+
+	w, ok := d.windows[key]
+	if !ok {
+		last, throttled := d.lastRun[key]
+		if throttled && now.Sub(last) >= d.window.interval {
+			delete(d.lastRun, key)
+		}
+
+		fctx, cancel := context.WithCancel(detachedContext{ctx})
+		w = &window{
+			flight: flight{ctx: fctx, cancel: cancel},
+			first:  now,
+			done:   make(chan struct{}),
+		}
+		d.windows[key] = w
+		w.timer = time.AfterFunc(d.window.delay(w, last, now), func() { d.fire(key, w) })
+	} else if d.window.interval == 0 {
+		// a trailing window restarts its quiet period on each submission
+		w.due = false
+		w.timer.Reset(d.window.delay(w, time.Time{}, now))
+	}
+
+	w.action = a
+	w.waiters++
+
+	d.mtx.Unlock()
+
+	select {
+	case <-w.done:
+		if w.err == nil {
+			shareResult(a, w.val)
+		}
+		return w.err
+	case <-ctx.Done():
+		d.leaveWindow(key, w)
+		return ctx.Err()
+	}
+}
+
+// fire executes the most recent submission of w, unless it was abandoned. If
+// a duplicate is still executing, w is instead executed once it returns.
+func (d *Debouncer) fire(key string, w *window) {
+	now := time.Now()
+
+	d.mtx.Lock()
+	if d.windows[key] != w {
+		d.mtx.Unlock()
+		return
+	}
+
+	if _, ok := d.running[key]; ok {
+		w.due = true
+		d.mtx.Unlock()
+		return
+	}
+
+	delete(d.windows, key)
+	d.running[key] = w
+	if d.window.interval > 0 {
+		d.lastRun[key] = now
+		time.AfterFunc(d.window.interval, func() { d.forgetRun(key, now) })
+	}
+	a := w.action
+	d.mtx.Unlock()
+
+	w.err = a.Execute(w.ctx)
+	w.val = resultOf(a)
+	d.remember(key, typeOf(a), &w.flight, w.val, w.err, time.Now())
+
+	w.cancel()
+	close(w.done)
+
+	d.mtx.Lock()
+	delete(d.running, key)
+	next, ok := d.windows[key]
+	d.mtx.Unlock()
+
+	if ok && next.due {
+		go d.fire(key, next)
+	}
+}
+
+// forgetRun discards the start of the last throttled execution for key once
+// its interval has elapsed, unless a later execution has since started.
+func (d *Debouncer) forgetRun(key string, start time.Time) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if last, ok := d.lastRun[key]; ok && last.Equal(start) {
+		delete(d.lastRun, key)
+	}
+}
+
+// leaveWindow unregisters a caller waiting on w. Once no callers remain, a
+// pending window is abandoned and an executing one is cancelled.
+func (d *Debouncer) leaveWindow(key string, w *window) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if w.waiters--; w.waiters > 0 {
+		return
+	}
+
+	w.cancel()
+	if d.windows[key] == w {
+		w.timer.Stop()
+		delete(d.windows, key)
+	}
+}
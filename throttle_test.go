@@ -0,0 +1,166 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebounceTrailing(t *testing.T) {
+	t.Parallel()
+
+	t.Run("collapse", func(t *testing.T) {
+		t.Parallel()
+
+		exec := Debounce(Sequential{}, DebounceTrailing(20*time.Millisecond, 0))
+
+		var mtx sync.Mutex
+		var ran []string
+		act := func(label string) Action {
+			return Named("reindex", "42", func(context.Context) error {
+				mtx.Lock()
+				ran = append(ran, label)
+				mtx.Unlock()
+				return nil
+			})
+		}
+
+		wg := &sync.WaitGroup{}
+		for _, label := range []string{"a", "b", "c"} {
+			wg.Add(1)
+			go func(a Action) {
+				defer wg.Done()
+				assert.NoError(t, exec.Execute(context.Background(), a))
+			}(act(label))
+			time.Sleep(5 * time.Millisecond)
+		}
+		wg.Wait()
+
+		assert.Equal(t, []string{"c"}, ran)
+	})
+
+	t.Run("max wait", func(t *testing.T) {
+		t.Parallel()
+
+		exec := Debounce(Sequential{}, DebounceTrailing(100*time.Millisecond, 30*time.Millisecond))
+
+		var ct uint32
+		act := Named("reindex", "42", func(context.Context) error {
+			atomic.AddUint32(&ct, 1)
+			return nil
+		})
+
+		start := time.Now()
+		res := make(chan error)
+		go func() { res <- exec.Execute(context.Background(), act) }()
+
+		time.Sleep(10 * time.Millisecond)
+		assert.NoError(t, exec.Execute(context.Background(), act))
+		assert.NoError(t, <-res)
+
+		assert.True(t, time.Since(start) < 100*time.Millisecond)
+		assert.Equal(t, uint32(1), atomic.LoadUint32(&ct))
+	})
+
+	t.Run("no overlap", func(t *testing.T) {
+		t.Parallel()
+
+		exec := Debounce(Sequential{}, DebounceTrailing(5*time.Millisecond, 0))
+
+		var ct, running, peak int32
+		act := Named("reindex", "42", func(context.Context) error {
+			atomic.AddInt32(&ct, 1)
+			if n := atomic.AddInt32(&running, 1); n > atomic.LoadInt32(&peak) {
+				atomic.StoreInt32(&peak, n)
+			}
+			time.Sleep(50 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil
+		})
+
+		res := make(chan error)
+		go func() { res <- exec.Execute(context.Background(), act) }()
+
+		// submit a duplicate while the first is executing
+		time.Sleep(20 * time.Millisecond)
+		assert.NoError(t, exec.Execute(context.Background(), act))
+		assert.NoError(t, <-res)
+
+		assert.Equal(t, int32(2), atomic.LoadInt32(&ct))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&peak))
+	})
+
+	t.Run("abandoned", func(t *testing.T) {
+		t.Parallel()
+
+		exec := Debounce(Sequential{}, DebounceTrailing(20*time.Millisecond, 0))
+
+		var ct uint32
+		act := Named("reindex", "42", func(context.Context) error {
+			atomic.AddUint32(&ct, 1)
+			return nil
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		assert.True(t, errors.Is(exec.Execute(ctx, act), context.DeadlineExceeded))
+
+		time.Sleep(40 * time.Millisecond)
+		assert.Zero(t, atomic.LoadUint32(&ct))
+	})
+}
+
+func TestDebounceThrottle(t *testing.T) {
+	t.Parallel()
+
+	exec := Debounce(Sequential{}, DebounceThrottle(50*time.Millisecond))
+
+	var ct uint32
+	act := Named("reindex", "42", func(context.Context) error {
+		atomic.AddUint32(&ct, 1)
+		return nil
+	})
+
+	// the first execution is immediate
+	start := time.Now()
+	assert.NoError(t, exec.Execute(context.Background(), act))
+	assert.True(t, time.Since(start) < 25*time.Millisecond)
+
+	// later duplicates wait for the interval and collapse into one execution
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, exec.Execute(context.Background(), act))
+		}()
+	}
+	wg.Wait()
+
+	assert.True(t, time.Since(start) >= 50*time.Millisecond)
+	assert.Equal(t, uint32(2), atomic.LoadUint32(&ct))
+}
+
+func TestDebounceThrottlePrune(t *testing.T) {
+	t.Parallel()
+
+	exec := Debounce(Sequential{}, DebounceThrottle(5*time.Millisecond))
+
+	noop := func(context.Context) error { return nil }
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, exec.Execute(context.Background(), Named("user", strconv.Itoa(i), noop)))
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	exec.mtx.Lock()
+	defer exec.mtx.Unlock()
+	assert.Empty(t, exec.lastRun)
+}
@@ -0,0 +1,98 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrActionTimeout is returned by the Timeout executor when an Action exceeds
+// its own time budget, distinguishing it from the cancellation of the ctx
+// passed to Execute.
+var ErrActionTimeout = errors.New("action timed out")
+
+type timeout struct {
+	ex      Interface
+	dur     time.Duration
+	perType map[string]time.Duration
+}
+
+// Timeout decorates the passed in executor, giving each Action its own
+// deadline independent of the ctx passed to Execute. NamedActions use the
+// duration in perType for their Type if present; all other Actions use dur. A
+// duration less than or equal to zero disables the timeout.
+func Timeout(e Interface, dur time.Duration, perType map[string]time.Duration) Interface {
+	return timeout{
+		ex:      e,
+		dur:     dur,
+		perType: perType,
+	}
+}
+
+func (t timeout) Execute(ctx context.Context, actions ...Action) error {
+	wrapped := make([]Action, len(actions))
+
+	for i, a := range actions {
+		if na, ok := a.(NamedAction); ok {
+			dur, ok := t.perType[na.Type()]
+			if !ok {
+				dur = t.dur
+			}
+
+			wrapped[i] = namedTimeoutAction{
+				NamedAction: na,
+				dur:         dur,
+			}
+		} else {
+			wrapped[i] = timeoutAction{
+				Action: a,
+				dur:    t.dur,
+			}
+		}
+	}
+
+	return t.ex.Execute(ctx, wrapped...)
+}
+
+type namedTimeoutAction struct {
+	NamedAction
+	dur time.Duration
+}
+
+func (a namedTimeoutAction) Execute(ctx context.Context) error {
+	return executeWithTimeout(ctx, a.NamedAction, a.dur)
+}
+
+func (a namedTimeoutAction) Unwrap() Action { return a.NamedAction }
+
+type timeoutAction struct {
+	Action
+	dur time.Duration
+}
+
+func (a timeoutAction) Execute(ctx context.Context) error {
+	return executeWithTimeout(ctx, a.Action, a.dur)
+}
+
+func (a timeoutAction) Unwrap() Action { return a.Action }
+
+func executeWithTimeout(ctx context.Context, a Action, dur time.Duration) error {
+	if dur <= 0 {
+		return a.Execute(ctx)
+	}
+
+	actx, cancel := context.WithTimeout(ctx, dur)
+	defer cancel()
+
+	err := a.Execute(actx)
+
+	// only attribute the failure to the timeout if the budget was exhausted
+	// while the parent ctx is still alive.
+	if err != nil && actx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		return ErrActionTimeout
+	}
+
+	return err
+}
+
+var _ Interface = timeout{}
@@ -0,0 +1,81 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	t.Parallel()
+
+	waitForCancel := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ex := Timeout(Parallel{}, time.Second, nil)
+
+		err := ex.Execute(context.Background(),
+			ActionFunc(func(ctx context.Context) error {
+				_, ok := ctx.Deadline()
+				assert.True(t, ok)
+				return nil
+			}))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("exceeded", func(t *testing.T) {
+		t.Parallel()
+
+		ex := Timeout(Parallel{}, time.Millisecond, nil)
+
+		err := ex.Execute(context.Background(), ActionFunc(waitForCancel))
+
+		assert.True(t, errors.Is(err, ErrActionTimeout))
+		assert.False(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("per type", func(t *testing.T) {
+		t.Parallel()
+
+		ex := Timeout(Sequential{FailOpen: true}, time.Millisecond, map[string]time.Duration{
+			"slow": time.Hour,
+		})
+
+		err := ex.Execute(context.Background(),
+			Named("slow", "1", func(ctx context.Context) error {
+				deadline, _ := ctx.Deadline()
+				assert.True(t, time.Until(deadline) > time.Minute)
+				return nil
+			}),
+			Named("fast", "2", waitForCancel))
+
+		var me MultiError
+		if assert.True(t, errors.As(err, &me)) && assert.Len(t, me, 1) {
+			assert.Equal(t, "fast", me[0].Type)
+			assert.Equal(t, ErrActionTimeout, me[0].Err)
+		}
+	})
+
+	t.Run("caller cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		ex := Timeout(Parallel{}, time.Hour, nil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		err := ex.Execute(ctx, ActionFunc(waitForCancel))
+
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.False(t, errors.Is(err, ErrActionTimeout))
+	})
+}
@@ -0,0 +1,267 @@
+package executor
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// A WeightedAction describes an Action that consumes more (or less) capacity
+// than others when limited by ControlFlow or a WeightedPool.
+type WeightedAction interface {
+	Action
+
+	// Weight returns the capacity consumed by the Action. Values less than one
+	// are treated as one.
+	Weight() int64
+}
+
+type weightedAction struct {
+	Action
+	weight int64
+}
+
+func (a weightedAction) Weight() int64  { return a.weight }
+func (a weightedAction) Unwrap() Action { return a.Action }
+
+type namedWeightedAction struct {
+	NamedAction
+	weight int64
+}
+
+func (a namedWeightedAction) Weight() int64  { return a.weight }
+func (a namedWeightedAction) Unwrap() Action { return a.NamedAction }
+
+// Weighted creates a WeightedAction consuming weight capacity. If a is a
+// NamedAction, so is the returned WeightedAction.
+func Weighted(a Action, weight int64) WeightedAction {
+	if na, ok := a.(NamedAction); ok {
+		return namedWeightedAction{NamedAction: na, weight: weight}
+	}
+	return weightedAction{Action: a, weight: weight}
+}
+
+// weightOf returns the capacity consumed by a, which is one unless a is a
+// WeightedAction.
+func weightOf(a Action) int64 {
+	if wa, ok := asAction[WeightedAction](a); ok && wa.Weight() > 1 {
+		return wa.Weight()
+	}
+	return 1
+}
+
+// totalWeight returns the combined capacity consumed by actions.
+func totalWeight(actions []Action) int64 {
+	var total int64
+	for _, a := range actions {
+		total += weightOf(a)
+	}
+	return total
+}
+
+// checkWeights returns an ActionError wrapping a LimitError for the first
+// Action that could never fit within limit.
+func checkWeights(actions []Action, limit int64) error {
+	for i, a := range actions {
+		if w := weightOf(a); w > limit {
+			return newActionError(i, a, &LimitError{Limit: limit, Requested: w})
+		}
+	}
+	return nil
+}
+
+// A WeightedPoolOption configures the behavior of a WeightedPool.
+type WeightedPoolOption func(*WeightedPool)
+
+// WeightedFailOpen causes the WeightedPool to execute all Actions passed to
+// Execute regardless of the failure of others. Any errors are returned as a
+// MultiError.
+func WeightedFailOpen() WeightedPoolOption {
+	return func(p *WeightedPool) { p.failOpen = true }
+}
+
+// DefaultMaxBypass is the number of Actions permitted to start ahead of a
+// pending Action that does not fit, unless configured by WeightedMaxBypass.
+const DefaultMaxBypass = 16
+
+// WeightedMaxBypass limits how many smaller Actions may start ahead of the
+// oldest pending Action that does not yet fit. Once reached, no further Actions
+// are started until it does, so that large Actions are not starved by a steady
+// stream of smaller ones. A value of zero or less starts Actions strictly in
+// order.
+func WeightedMaxBypass(n int) WeightedPoolOption {
+	return func(p *WeightedPool) { p.maxBypass = n }
+}
+
+// WeightedPool is an Executor Interface that limits the combined Weight of the
+// Actions executing concurrently, rather than their number. Pending Actions are
+// started in the order they were passed to Execute as capacity frees up, with
+// smaller Actions permitted to start ahead of larger ones that do not yet fit,
+// up to the limit set by WeightedMaxBypass. Actions that are not
+// WeightedActions have a weight of one.
+type WeightedPool struct {
+	capacity  int64
+	failOpen  bool
+	maxBypass int
+
+	mtx     sync.Mutex
+	used    int64
+	pending *list.List // of *weightedTask
+}
+
+// NewWeightedPool creates a WeightedPool permitting Actions with a combined
+// Weight of up to capacity to execute concurrently.
+func NewWeightedPool(capacity int64, opts ...WeightedPoolOption) *WeightedPool {
+	p := &WeightedPool{
+		capacity:  capacity,
+		maxBypass: DefaultMaxBypass,
+		pending:   list.New(),
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Execute starts each Action once there is capacity for it, failing closed on
+// the first error or if ctx is cancelled. This method blocks until all started
+// Actions have returned. If any Action's Weight exceeds the pool's capacity,
+// nothing is executed and an ActionError wrapping a LimitError is returned. If
+// the pool fails open, all Actions are executed and their errors collected
+// instead.
+func (p *WeightedPool) Execute(ctx context.Context, actions ...Action) error {
+	qty := len(actions)
+	if qty == 0 {
+		return nil
+	}
+
+	if err := checkWeights(actions, p.capacity); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	res := make(chan poolResult, qty)
+	tasks := make([]*weightedTask, qty)
+
+	p.mtx.Lock()
+	for i, a := range actions {
+		t := &weightedTask{ctx: ctx, idx: i, act: a, weight: weightOf(a), res: res}
+		t.elem = p.pending.PushBack(t)
+		tasks[i] = t
+	}
+	p.dispatch()
+	p.mtx.Unlock()
+
+	var err error
+	var errs []error
+	if p.failOpen {
+		errs = make([]error, qty)
+	}
+
+	done := ctx.Done()
+	for remaining := qty; remaining > 0; {
+		select {
+		case <-done:
+			// fail any actions that have yet to start
+			p.abandon(tasks, ctx.Err())
+			done = nil
+		case r := <-res:
+			remaining--
+			if r.err == nil {
+				continue
+			}
+
+			if p.failOpen {
+				errs[r.idx] = r.err
+			} else if err == nil {
+				err = newActionError(r.idx, r.act, r.err)
+				cancel()
+			}
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return collectErrors(actions, errs)
+}
+
+// dispatch starts each pending task that fits in the remaining capacity,
+// stopping at the oldest task that does not once it has been bypassed
+// maxBypass times. The caller must hold the lock.
+func (p *WeightedPool) dispatch() {
+	var blocked *weightedTask // the oldest pending task that does not fit
+
+	for e := p.pending.Front(); e != nil && p.used < p.capacity; {
+		next := e.Next()
+
+		t := e.Value.(*weightedTask)
+		switch {
+		case t.ctx.Err() != nil: // the call was cancelled before t started
+			p.pending.Remove(e)
+			t.elem = nil
+			t.res <- poolResult{idx: t.idx, act: t.act, err: t.ctx.Err()}
+		case p.used+t.weight <= p.capacity:
+			p.pending.Remove(e)
+			t.elem = nil
+			p.used += t.weight
+			go p.run(t)
+
+			if blocked != nil {
+				if blocked.bypassed++; blocked.bypassed >= p.maxBypass {
+					return
+				}
+			}
+		case blocked == nil:
+			if t.bypassed >= p.maxBypass {
+				return
+			}
+			blocked = t
+		}
+
+		e = next
+	}
+}
+
+// run executes t, releasing its capacity to pending tasks once it returns.
+func (p *WeightedPool) run(t *weightedTask) {
+	err := t.act.Execute(t.ctx)
+
+	p.mtx.Lock()
+	p.used -= t.weight
+	p.dispatch()
+	p.mtx.Unlock()
+
+	t.res <- poolResult{idx: t.idx, act: t.act, err: err}
+}
+
+// abandon removes any of tasks that have yet to start, failing them with err.
+func (p *WeightedPool) abandon(tasks []*weightedTask, err error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	for _, t := range tasks {
+		if t.elem != nil {
+			p.pending.Remove(t.elem)
+			t.elem = nil
+			t.res <- poolResult{idx: t.idx, act: t.act, err: err}
+		}
+	}
+}
+
+type weightedTask struct {
+	ctx      context.Context
+	idx      int
+	act      Action
+	weight   int64
+	bypassed int // number of tasks started ahead of this one
+	res      chan<- poolResult
+	elem     *list.Element // position in the pending list, or nil once started
+}
+
+var _ Interface = (*WeightedPool)(nil)
@@ -0,0 +1,225 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeighted(t *testing.T) {
+	t.Parallel()
+
+	noop := func(context.Context) error { return nil }
+
+	wa := Weighted(ActionFunc(noop), 5)
+	assert.Equal(t, int64(5), weightOf(wa))
+	_, named := wa.(NamedAction)
+	assert.False(t, named)
+
+	wa = Weighted(Named("foo", "bar", noop), 3)
+	assert.Equal(t, int64(3), weightOf(wa))
+	if na, ok := wa.(NamedAction); assert.True(t, ok) {
+		assert.Equal(t, "foo", na.Type())
+	}
+
+	assert.Equal(t, int64(1), weightOf(ActionFunc(noop)))
+	assert.Equal(t, int64(1), weightOf(Weighted(ActionFunc(noop), 0)))
+	assert.Equal(t, int64(9), totalWeight([]Action{wa, Weighted(ActionFunc(noop), 5), ActionFunc(noop)}))
+}
+
+func TestWeightedPool(t *testing.T) {
+	t.Parallel()
+
+	noop := func(context.Context) error { return nil }
+
+	t.Run("capacity", func(t *testing.T) {
+		t.Parallel()
+
+		p := NewWeightedPool(10)
+
+		var used, peak int64
+		act := func(weight int64) Action {
+			return Weighted(ActionFunc(func(context.Context) error {
+				n := atomic.AddInt64(&used, weight)
+				for {
+					m := atomic.LoadInt64(&peak)
+					if n <= m || atomic.CompareAndSwapInt64(&peak, m, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				atomic.AddInt64(&used, -weight)
+				return nil
+			}), weight)
+		}
+
+		assert.NoError(t, p.Execute(context.Background(), act(6), act(6), act(3), act(1), act(10)))
+		assert.Equal(t, int64(10), atomic.LoadInt64(&peak))
+	})
+
+	t.Run("first fit", func(t *testing.T) {
+		t.Parallel()
+
+		p := NewWeightedPool(4)
+
+		var mtx sync.Mutex
+		var order []string
+		record := func(name string, weight int64) Action {
+			return Weighted(ActionFunc(func(context.Context) error {
+				mtx.Lock()
+				order = append(order, name)
+				mtx.Unlock()
+				time.Sleep(5 * time.Millisecond)
+				return nil
+			}), weight)
+		}
+
+		// the small action fits alongside the first while the second waits
+		assert.NoError(t, p.Execute(context.Background(), record("a", 3), record("b", 3), record("c", 1)))
+		if assert.Len(t, order, 3) {
+			assert.ElementsMatch(t, []string{"a", "c"}, order[:2])
+			assert.Equal(t, "b", order[2])
+		}
+	})
+
+	t.Run("max bypass", func(t *testing.T) {
+		t.Parallel()
+
+		p := NewWeightedPool(2, WeightedMaxBypass(1))
+
+		var mtx sync.Mutex
+		var order []string
+		record := func(name string, weight int64) Action {
+			return Weighted(ActionFunc(func(context.Context) error {
+				mtx.Lock()
+				order = append(order, name)
+				mtx.Unlock()
+				return nil
+			}), weight)
+		}
+
+		waitPending := func(n int) {
+			for {
+				p.mtx.Lock()
+				l := p.pending.Len()
+				p.mtx.Unlock()
+				if l == n {
+					return
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		wg := &sync.WaitGroup{}
+		execute := func(a Action) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, p.Execute(context.Background(), a))
+			}()
+		}
+
+		execute(ActionFunc(func(context.Context) error {
+			close(started)
+			<-release
+			return nil
+		}))
+		<-started
+
+		execute(record("heavy", 2))
+		waitPending(1)
+
+		// the first small action bypasses the heavy one, but the second may not
+		assert.NoError(t, p.Execute(context.Background(), record("small 1", 1)))
+		execute(record("small 2", 1))
+		waitPending(2)
+
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, []string{"small 1", "heavy", "small 2"}, order)
+	})
+
+	t.Run("too heavy", func(t *testing.T) {
+		t.Parallel()
+
+		p := NewWeightedPool(4)
+
+		var ct uint32
+		act := ActionFunc(func(context.Context) error {
+			atomic.AddUint32(&ct, 1)
+			return nil
+		})
+
+		err := p.Execute(context.Background(), act, Weighted(act, 5))
+
+		var ae *ActionError
+		var le *LimitError
+		if assert.True(t, errors.As(err, &ae)) && assert.True(t, errors.As(err, &le)) {
+			assert.Equal(t, 1, ae.Index)
+			assert.Equal(t, int64(4), le.Limit)
+			assert.Equal(t, int64(5), le.Requested)
+		}
+		assert.Zero(t, ct)
+	})
+
+	t.Run("fail closed", func(t *testing.T) {
+		t.Parallel()
+
+		p := NewWeightedPool(1)
+
+		fail := ActionFunc(func(context.Context) error { return errors.New("fail") })
+
+		err := p.Execute(context.Background(), fail, ActionFunc(noop), ActionFunc(noop))
+
+		var ae *ActionError
+		if assert.True(t, errors.As(err, &ae)) {
+			assert.Equal(t, 0, ae.Index)
+		}
+	})
+
+	t.Run("fail open", func(t *testing.T) {
+		t.Parallel()
+
+		p := NewWeightedPool(2, WeightedFailOpen())
+
+		fail := ActionFunc(func(context.Context) error { return errors.New("fail") })
+
+		err := p.Execute(context.Background(), fail, ActionFunc(noop), Weighted(fail, 2))
+
+		var me MultiError
+		if assert.True(t, errors.As(err, &me)) && assert.Len(t, me, 2) {
+			assert.Equal(t, 0, me[0].Index)
+			assert.Equal(t, 2, me[1].Index)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		p := NewWeightedPool(1)
+
+		release := make(chan struct{})
+		started := make(chan struct{})
+		go p.Execute(context.Background(), ActionFunc(func(context.Context) error {
+			close(started)
+			<-release
+			return nil
+		}))
+		<-started
+		defer close(release)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		err := p.Execute(ctx, ActionFunc(noop))
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+}